@@ -24,7 +24,7 @@ func GetAccessToken(ctx context.Context, appid, appSecret string) (at *WxAccessT
 	}
 
 	if at.ErrCode != 0 {
-		return nil, fmt.Errorf("get access_token error: %d, %s", at.ErrCode, at.ErrMsg)
+		return nil, ToError(int64(at.ErrCode), at.ErrMsg)
 	}
 
 	at.ExpiresTime = time.Now().Unix() + int64(at.ExpiresIn-200)
@@ -33,22 +33,35 @@ func GetAccessToken(ctx context.Context, appid, appSecret string) (at *WxAccessT
 }
 
 func GetStableToken(ctx context.Context, appid, appSecret string) (at *WxAccessToken, err error) {
+	return getStableToken(ctx, nil, appid, appSecret, false)
+}
+
+// RefreshStableToken 强制刷新模式获取 stable_token，之前的 access_token 会在5分钟后失效，每天调用次数有限制，仅在 access_token 失效时使用
+func RefreshStableToken(ctx context.Context, appid, appSecret string) (at *WxAccessToken, err error) {
+	return getStableToken(ctx, nil, appid, appSecret, true)
+}
+
+// c 为 nil 时使用默认的 http.Client
+func getStableToken(ctx context.Context, c *Client, appid, appSecret string, forceRefresh bool) (at *WxAccessToken, err error) {
+	if c == nil {
+		c = NewClient(nil).WithoutToken()
+	}
 
 	at = &WxAccessToken{}
-	URL := "https://api.weixin.qq.com/cgi-bin/stable_token"
+	URL := "/cgi-bin/stable_token"
 
 	bodyMap := make(BodyMap)
 	bodyMap.Set("grant_type", "client_credential")
 	bodyMap.Set("appid", appid)
 	bodyMap.Set("secret", appSecret)
-	bodyMap.Set("force_refresh", false)
+	bodyMap.Set("force_refresh", forceRefresh)
 
-	if err = DoRequestPost(ctx, URL, bodyMap, at); err != nil {
+	if err = c.DoRequestPost(ctx, URL, bodyMap, at); err != nil {
 		return nil, fmt.Errorf("do request get access_token: %w", err)
 	}
 
 	if at.ErrCode != 0 {
-		return nil, fmt.Errorf("get access_token error: %d, %s", at.ErrCode, at.ErrMsg)
+		return nil, ToError(int64(at.ErrCode), at.ErrMsg)
 	}
 
 	at.ExpiresTime = time.Now().Unix() + int64(at.ExpiresIn)
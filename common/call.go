@@ -0,0 +1,67 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/medreams/wechat/pkg/xhttp"
+)
+
+// Call 以 json 发送 POST 请求并将响应解析为 Resp，自动带上 access_token，errcode 不为0时返回 *APIError
+// req 可以是 BodyMap 或带 json tag 的结构体，由参数推断，如 Call[GetUserInfoRsp](ctx, client, uri, bodyMap)
+func Call[Resp, Req any](ctx context.Context, c *Client, uri string, req Req) (*Resp, error) {
+	body, err := toBodyMap(req)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := new(Resp)
+	if err := c.DoRequestPost(ctx, uri, body, rsp); err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	return rsp, nil
+}
+
+// CallGet 发送 GET 请求并将响应解析为 Resp，参数放在 uri 中
+func CallGet[Resp any](ctx context.Context, c *Client, uri string) (*Resp, error) {
+	rsp := new(Resp)
+	if err := c.DoRequestGet(ctx, uri, rsp); err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	return rsp, nil
+}
+
+// CallUpload 以 multipart/form-data 上传文件并将响应解析为 Resp，文件使用 BodyMap.SetFormFile 设置
+func CallUpload[Resp any](ctx context.Context, c *Client, uri string, body BodyMap) (*Resp, error) {
+	rsp := new(Resp)
+	if err := c.DoUploadFile(ctx, uri, body, rsp); err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	return rsp, nil
+}
+
+// 结构体转换为 BodyMap，经过拦截器、日志脱敏，数字保持原样
+func toBodyMap(req interface{}) (map[string]interface{}, error) {
+	switch v := req.(type) {
+	case nil:
+		return nil, nil
+	case BodyMap:
+		return v, nil
+	case map[string]interface{}:
+		return v, nil
+	}
+
+	bs, err := xhttp.JsonUnEscapeHtml(req)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal(%+v)：%w", req, err)
+	}
+	body := make(map[string]interface{})
+	decoder := json.NewDecoder(bytes.NewReader(bs))
+	decoder.UseNumber()
+	if err := decoder.Decode(&body); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal(%s)：%w", string(bs), err)
+	}
+	return body, nil
+}
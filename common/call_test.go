@@ -0,0 +1,57 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCall(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("access_token") != "TOKEN" {
+			t.Errorf("access_token = %s", r.URL.Query().Get("access_token"))
+		}
+		if r.URL.Path == "/cgi-bin/user/info" {
+			w.Write([]byte(`{"errcode":40003,"errmsg":"invalid openid"}`))
+			return
+		}
+		bs, _ := io.ReadAll(r.Body)
+		w.Write(bs)
+	}))
+	defer srv.Close()
+
+	type message struct {
+		WxCommonResponse
+		Id      int64  `json:"id"`
+		Content string `json:"content"`
+	}
+	c := NewClient(StaticTokenSource("TOKEN"), WithBaseURL(srv.URL))
+	ctx := context.Background()
+
+	// 结构体参数，大整数不丢失精度，不转义 html
+	req := message{Id: 1<<62 + 1, Content: "<a href=\"x\">&</a>"}
+	rsp, err := Call[message](ctx, c, "/echo", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsp.Id != req.Id || rsp.Content != req.Content {
+		t.Fatalf("Call() = %+v", rsp)
+	}
+
+	raw, err := Call[json.RawMessage](ctx, c, "/echo", BodyMap{"content": "<b>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(*raw) != `{"content":"<b>"}` {
+		t.Fatalf("Call() = %s", *raw)
+	}
+
+	var apiErr *APIError
+	if _, err := CallGet[WxCommonResponse](ctx, c, "/cgi-bin/user/info?openid=x"); !errors.As(err, &apiErr) || apiErr.Code != 40003 {
+		t.Fatalf("CallGet() = %v", err)
+	}
+}
@@ -0,0 +1,383 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL 微信接口默认域名
+	DefaultBaseURL = "https://api.weixin.qq.com"
+	// BackupBaseURL 微信接口备用域名，默认域名网络不可用时切换
+	BackupBaseURL = "https://api2.weixin.qq.com"
+)
+
+// Client 需要 access_token 的接口请求客户端，每次请求都从 TokenSource 获取 access_token
+type Client struct {
+	appid        string
+	tokenSource  TokenSource
+	httpClient   *http.Client
+	interceptors []Interceptor
+	roundTrip    RoundTripFunc // 包含拦截器，在 NewClient 中创建
+	baseURL      string
+	failover     []string // 备用域名，为 nil 时使用默认域名则切换到 BackupBaseURL
+	timeout      time.Duration
+	retry        RetryPolicy
+	logger       *slog.Logger
+	noToken      bool // 不需要 access_token 的接口
+}
+
+type ClientOption func(c *Client)
+
+// WithHTTPClient 设置发起请求使用的 http.Client
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithAppId 设置当前账号的 AppID，拦截器可通过 Request.AppId 区分账号
+func WithAppId(appid string) ClientOption {
+	return func(c *Client) {
+		c.appid = appid
+	}
+}
+
+// WithInterceptors 添加请求拦截器，按添加顺序执行
+func WithInterceptors(interceptors ...Interceptor) ClientOption {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, interceptors...)
+	}
+}
+
+// WithRateLimiter 按接口路径限流，在其他拦截器之前等待
+func WithRateLimiter(limiter *RateLimiter) ClientOption {
+	return func(c *Client) {
+		if limiter != nil {
+			c.interceptors = append([]Interceptor{limiter.Interceptor()}, c.interceptors...)
+		}
+	}
+}
+
+// WithBaseURL 设置接口域名，默认 https://api.weixin.qq.com，如 https://sh.api.weixin.qq.com 或本地测试服务
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithFailoverBaseURLs 设置备用域名，主域名网络不可用时依次切换；不传参数时不切换
+// 未设置时，使用默认域名则切换到 https://api2.weixin.qq.com，使用自定义域名则不切换
+func WithFailoverBaseURLs(baseURLs ...string) ClientOption {
+	return func(c *Client) {
+		c.failover = make([]string, 0, len(baseURLs))
+		for _, baseURL := range baseURLs {
+			c.failover = append(c.failover, strings.TrimRight(baseURL, "/"))
+		}
+	}
+}
+
+// WithTimeout 设置单次接口调用（包含重试）的超时时间
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetryPolicy 设置请求失败重试策略
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithLogger 使用 Printf 输出 Info 及以上级别的日志，默认不输出
+func WithLogger(logger Logger) ClientOption {
+	return func(c *Client) {
+		if logger != nil {
+			c.logger = slog.New(&printfHandler{logger: logger})
+		}
+	}
+}
+
+// WithSlog 使用 slog 输出日志，默认不输出；请求体、响应体只在 Debug 级别输出
+func WithSlog(logger *slog.Logger) ClientOption {
+	return func(c *Client) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
+
+func NewClient(ts TokenSource, opts ...ClientOption) *Client {
+	c := &Client{
+		tokenSource: ts,
+		logger:      discardLogger,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	// 日志在最内层，记录每次实际发出的请求
+	interceptors := append(append([]Interceptor{}, c.interceptors...), LoggingInterceptor(c.logger))
+	rt, appid := ChainInterceptors(httpRoundTrip(c.httpClient), interceptors...), c.appid
+	c.roundTrip = func(ctx context.Context, req *Request) (*Response, error) {
+		req.AppId = appid
+		return rt(ctx, req)
+	}
+	return c
+}
+
+// TokenSource 当前使用的 access_token 来源
+func (c *Client) TokenSource() TokenSource {
+	return c.tokenSource
+}
+
+// WithTokenSource 返回使用 ts 获取 access_token 的副本，其他配置不变
+func (c *Client) WithTokenSource(ts TokenSource) *Client {
+	clone := *c
+	clone.tokenSource = ts
+	return &clone
+}
+
+// WithoutToken 返回不带 access_token 的副本，用于获取 access_token、code 换取 session 等接口，其他配置不变
+func (c *Client) WithoutToken() *Client {
+	clone := *c
+	clone.noToken = true
+	return &clone
+}
+
+// HTTPClient 发起请求使用的 http.Client，为 nil 时使用默认的 http.Client
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
+func (c *Client) DoRequestGet(ctx context.Context, uri string, ptr interface{}) error {
+	return c.do(ctx, http.MethodGet, uri, true, func(ctx context.Context, uri string) error {
+		return doRequestGet(ctx, c.roundTrip, uri, ptr)
+	})
+}
+
+func (c *Client) DoRequestGetByte(ctx context.Context, uri string) (bs []byte, err error) {
+	err = c.do(ctx, http.MethodGet, uri, true, func(ctx context.Context, uri string) (err error) {
+		bs, err = doRequestGetByte(ctx, c.roundTrip, uri)
+		return err
+	})
+	return bs, err
+}
+
+func (c *Client) DoRequestPost(ctx context.Context, uri string, body map[string]interface{}, ptr interface{}) error {
+	return c.do(ctx, http.MethodPost, uri, isIdempotent(body), func(ctx context.Context, uri string) error {
+		return doRequestPost(ctx, c.roundTrip, uri, body, ptr)
+	})
+}
+
+func (c *Client) DoRequestPostByte(ctx context.Context, uri string, body map[string]interface{}) (bs []byte, err error) {
+	err = c.do(ctx, http.MethodPost, uri, isIdempotent(body), func(ctx context.Context, uri string) (err error) {
+		bs, err = doRequestPostByte(ctx, c.roundTrip, uri, body)
+		return err
+	})
+	return bs, err
+}
+
+func (c *Client) DoUploadFile(ctx context.Context, uri string, body map[string]interface{}, ptr interface{}) error {
+	request := func(ctx context.Context, uri string) error {
+		return doUploadFile(ctx, c.roundTrip, uri, body, ptr)
+	}
+	// 文件只能读取一次时只请求一次，返回真实的错误而不是 ErrFileConsumed
+	if !rewindable(body) {
+		ctx, cancel := c.withTimeout(ctx)
+		defer cancel()
+		return c.doOnce(ctx, uri, request)
+	}
+	return c.do(ctx, http.MethodPost, uri, isIdempotent(body), request)
+}
+
+// DoRequestGetStream 以流的方式读取文件等二进制响应，没有大小限制，读取后需要 Close
+// 设置了超时时间时，超时时间包含读取响应的时间
+func (c *Client) DoRequestGetStream(ctx context.Context, uri string) (*Stream, error) {
+	return c.doStream(ctx, http.MethodGet, uri, nil)
+}
+
+// DoRequestPostStream 以流的方式读取文件等二进制响应，没有大小限制，读取后需要 Close
+func (c *Client) DoRequestPostStream(ctx context.Context, uri string, body map[string]interface{}) (*Stream, error) {
+	return c.doStream(ctx, http.MethodPost, uri, body)
+}
+
+func (c *Client) doStream(ctx context.Context, method, uri string, body map[string]interface{}) (stream *Stream, err error) {
+	ctx, cancel := c.withTimeout(ctx)
+	err = c.doWithToken(ctx, method, uri, method == http.MethodGet || isIdempotent(body), func(ctx context.Context, uri string) (err error) {
+		stream, err = doRequestStream(ctx, c.roundTrip, newRequest(method, uri, body))
+		return err
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// 读取完响应后才能取消 ctx
+	stream.ReadCloser = &cancelReadCloser{ReadCloser: stream.ReadCloser, cancel: cancel}
+	return stream, nil
+}
+
+type cancelReadCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelReadCloser) Close() error {
+	defer r.cancel()
+	return r.ReadCloser.Close()
+}
+
+// 设置了超时时间时返回带超时的 ctx
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout > 0 {
+		return context.WithTimeout(ctx, c.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// 带上 access_token 发起请求，返回 access_token 失效时强制刷新并重试一次；noToken 时直接请求
+// uri 为 / 开头的接口路径时使用设置的域名，也可以是完整的地址
+func (c *Client) do(ctx context.Context, method, uri string, idempotent bool, request func(ctx context.Context, uri string) error) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	return c.doWithToken(ctx, method, uri, idempotent, request)
+}
+
+func (c *Client) doWithToken(ctx context.Context, method, uri string, idempotent bool, request func(ctx context.Context, uri string) error) error {
+	if c.tokenSource == nil && !c.noToken {
+		return errors.New("token source is nil")
+	}
+
+	if c.noToken {
+		return c.sendFailover(ctx, method, uri, "", idempotent, request)
+	}
+
+	token, err := c.tokenSource.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("get access_token: %w", err)
+	}
+
+	err = c.sendFailover(ctx, method, uri, token, idempotent, request)
+	if !IsTokenExpired(err) {
+		return err
+	}
+
+	refresher, ok := c.tokenSource.(TokenRefresher)
+	if !ok {
+		return err
+	}
+	c.logger.LogAttrs(ctx, slog.LevelInfo, "access_token invalid, refresh and retry",
+		slog.String("method", method), slog.String("url", RedactURL(uri)))
+	if token, err = refresher.RefreshToken(ctx, token); err != nil {
+		return fmt.Errorf("refresh access_token: %w", err)
+	}
+
+	return c.sendFailover(ctx, method, uri, token, idempotent, request)
+}
+
+// 只使用主域名请求一次，不重试、不切换域名，access_token 失效时也不重放
+func (c *Client) doOnce(ctx context.Context, uri string, request func(ctx context.Context, uri string) error) error {
+	tokenURI := resolveURL(uri, c.baseURLs(uri)[0])
+	if !c.noToken {
+		if c.tokenSource == nil {
+			return errors.New("token source is nil")
+		}
+		token, err := c.tokenSource.Token(ctx)
+		if err != nil {
+			return fmt.Errorf("get access_token: %w", err)
+		}
+		if tokenURI, err = withAccessToken(tokenURI, token); err != nil {
+			return err
+		}
+	}
+	return request(ctx, tokenURI)
+}
+
+// 依次使用主域名、备用域名请求，网络不可用时切换到下一个域名
+func (c *Client) sendFailover(ctx context.Context, method, uri, token string, idempotent bool, request func(ctx context.Context, uri string) error) (err error) {
+	baseURLs := c.baseURLs(uri)
+	for i, baseURL := range baseURLs {
+		fullURI := resolveURL(uri, baseURL)
+		tokenURI := fullURI
+		if token != "" {
+			if tokenURI, err = withAccessToken(fullURI, token); err != nil {
+				return err
+			}
+		}
+
+		err = c.send(ctx, method, fullURI, tokenURI, idempotent, request)
+		if err == nil || i == len(baseURLs)-1 || !canFailover(ctx, method, err) {
+			return err
+		}
+		c.logger.LogAttrs(ctx, slog.LevelWarn, "request failed, switch base url",
+			slog.String("method", method), slog.String("url", RedactURL(fullURI)), slog.String("next", baseURLs[i+1]), slog.Any("error", RedactError(err)))
+	}
+	return err
+}
+
+// 按重试策略发起请求，uri 用于日志，不包含 access_token
+func (c *Client) send(ctx context.Context, method, uri, tokenURI string, idempotent bool, request func(ctx context.Context, uri string) error) error {
+	for attempt := 1; ; attempt++ {
+		err := request(ctx, tokenURI)
+		if err == nil || attempt >= c.retry.MaxAttempts || !c.retry.shouldRetry(ctx, idempotent, err) {
+			return err
+		}
+
+		c.logger.LogAttrs(ctx, slog.LevelWarn, "request failed, retry",
+			slog.String("method", method), slog.String("url", RedactURL(uri)), slog.Int("attempt", attempt), slog.Any("error", RedactError(err)))
+		if sleepErr := sleepContext(ctx, c.retry.backoff(attempt)); sleepErr != nil {
+			return err
+		}
+	}
+}
+
+// 请求 uri 可使用的域名，主域名在前；非微信接口域名的完整地址不切换域名
+func (c *Client) baseURLs(uri string) []string {
+	if !strings.HasPrefix(uri, "/") && !strings.HasPrefix(uri, DefaultBaseURL) {
+		return []string{""}
+	}
+
+	baseURL := c.baseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	failover := c.failover
+	if failover == nil && baseURL == DefaultBaseURL {
+		failover = []string{BackupBaseURL}
+	}
+	return append([]string{baseURL}, failover...)
+}
+
+// 使用 baseURL 替换默认域名，或拼接在接口路径前
+func resolveURL(uri, baseURL string) string {
+	switch {
+	case baseURL == "":
+		return uri
+	case strings.HasPrefix(uri, "/"):
+		return baseURL + uri
+	case strings.HasPrefix(uri, DefaultBaseURL):
+		return baseURL + strings.TrimPrefix(uri, DefaultBaseURL)
+	}
+	return uri
+}
+
+// 在 uri 的查询参数中加上 access_token
+func withAccessToken(uri, token string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("url.Parse(%s)：%w", uri, err)
+	}
+	query := u.Query()
+	query.Set("access_token", token)
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
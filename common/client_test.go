@@ -0,0 +1,361 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/medreams/wechat/pkg/util"
+)
+
+type countingTransport struct {
+	calls int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.calls, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClientHTTPClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cgi-bin/stable_token":
+			w.Write([]byte(`{"access_token":"TOKEN","expires_in":7200}`))
+		case "/cgi-bin/get_api_domain_ip":
+			if r.URL.Query().Get("access_token") != "TOKEN" {
+				w.Write([]byte(`{"errcode":40014,"errmsg":"invalid access_token"}`))
+				return
+			}
+			w.Write([]byte(`{"ip_list":["127.0.0.1"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	transport := &countingTransport{}
+	m := NewTokenManager("wx_http_client", "secret", NewMemoryTokenStore())
+	c := NewClient(m, WithHTTPClient(&http.Client{Transport: transport}), WithBaseURL(srv.URL))
+	m.SetClient(c)
+
+	var rsp struct {
+		IpList []string `json:"ip_list"`
+	}
+	if err := c.DoRequestGet(context.Background(), DefaultBaseURL+"/cgi-bin/get_api_domain_ip", &rsp); err != nil {
+		t.Fatal(err)
+	}
+	if len(rsp.IpList) != 1 {
+		t.Fatalf("unexpected response: %+v", rsp)
+	}
+	// stable_token 和接口请求都使用注入的 http.Client
+	if calls := atomic.LoadInt32(&transport.calls); calls != 2 {
+		t.Fatalf("transport calls = %d, want 2", calls)
+	}
+}
+
+func TestClientFailover(t *testing.T) {
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer backup.Close()
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
+	c := NewClient(StaticTokenSource("TOKEN"), WithBaseURL(downURL), WithFailoverBaseURLs(backup.URL))
+	rsp := &WxCommonResponse{}
+	if err := c.DoRequestPost(context.Background(), "/cgi-bin/menu/create", BodyMap{"button": []string{}}, rsp); err != nil {
+		t.Fatal(err)
+	}
+
+	c = NewClient(StaticTokenSource("TOKEN"), WithBaseURL(downURL))
+	if err := c.DoRequestGet(context.Background(), "/cgi-bin/menu/get", rsp); err == nil {
+		t.Fatal("custom base url should not fail over by default")
+	}
+}
+
+func TestClientErrorRedacted(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
+	c := NewClient(StaticTokenSource("SECRET_TOKEN"), WithBaseURL(downURL), WithFailoverBaseURLs())
+	err := c.DoRequestGet(context.Background(), "/cgi-bin/get_api_domain_ip", &WxCommonResponse{})
+	if err == nil {
+		t.Fatal("expected network error")
+	}
+	if strings.Contains(err.Error(), "SECRET_TOKEN") {
+		t.Fatalf("error leaks access_token: %v", err)
+	}
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) || !strings.Contains(urlErr.URL, "access_token=%2A%2A%2A") {
+		t.Fatalf("url error not redacted: %v", err)
+	}
+}
+
+func TestClientInterceptors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer srv.Close()
+
+	var order []string
+	trace := func(name string) Interceptor {
+		return func(ctx context.Context, req *Request, next RoundTripFunc) (*Response, error) {
+			order = append(order, name)
+			req.Header.Set("X-Trace", name)
+			res, err := next(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			if res.StatusCode != http.StatusOK || string(res.Body) != `{"errcode":0,"errmsg":"ok"}` {
+				t.Fatalf("unexpected response: %d %s", res.StatusCode, res.Body)
+			}
+			return res, nil
+		}
+	}
+
+	var seen *Request
+	record := func(ctx context.Context, req *Request, next RoundTripFunc) (*Response, error) {
+		seen = req
+		return next(ctx, req)
+	}
+
+	c := NewClient(StaticTokenSource("TOKEN"), WithBaseURL(srv.URL), WithInterceptors(trace("a"), trace("b"), record))
+	if err := c.DoRequestPost(context.Background(), "/cgi-bin/tags/create", BodyMap{"name": "test"}, &WxCommonResponse{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 2 || order[0] != "a" || order[1] != "b" {
+		t.Fatalf("interceptor order = %v", order)
+	}
+	if seen.Method != http.MethodPost || seen.Body["name"] != "test" || seen.Header.Get("X-Trace") != "b" {
+		t.Fatalf("unexpected request: %+v", seen)
+	}
+	if got := RedactURL(seen.URL); got != srv.URL+"/cgi-bin/tags/create?access_token=%2A%2A%2A" {
+		t.Fatalf("RedactURL = %s", got)
+	}
+}
+
+func TestClientRetryPolicy(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		switch r.URL.Path {
+		case "/busy":
+			if n < 3 {
+				w.Write([]byte(`{"errcode":-1,"errmsg":"system error"}`))
+				return
+			}
+			w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	policy := RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, Jitter: 0.5}
+	c := NewClient(StaticTokenSource("TOKEN"), WithBaseURL(srv.URL), WithRetryPolicy(policy))
+	ctx := context.Background()
+
+	if err := c.DoRequestGet(ctx, "/busy", &WxCommonResponse{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.SwapInt32(&calls, 0); n != 3 {
+		t.Fatalf("calls = %d, want 3", n)
+	}
+	if err := c.DoRequestPost(ctx, "/busy", BodyMap{"client_msg_id": "id"}, &WxCommonResponse{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.SwapInt32(&calls, 0); n != 3 {
+		t.Fatalf("calls = %d, want 3", n)
+	}
+
+	// errcode 为 -1 时不重试不带 client_msg_id 的 POST 请求
+	if err := c.DoRequestPost(ctx, "/busy", BodyMap{"touser": "openid"}, &WxCommonResponse{}); err == nil {
+		t.Fatal("want error")
+	}
+	if n := atomic.SwapInt32(&calls, 0); n != 1 {
+		t.Fatalf("calls = %d, want 1", n)
+	}
+
+	// 5xx 不重试不带 client_msg_id 的 POST 请求
+	if err := c.DoRequestPost(ctx, "/bad_gateway", BodyMap{"touser": "openid"}, &WxCommonResponse{}); err == nil {
+		t.Fatal("want error")
+	}
+	if n := atomic.SwapInt32(&calls, 0); n != 1 {
+		t.Fatalf("calls = %d, want 1", n)
+	}
+
+	if err := c.DoRequestPost(ctx, "/bad_gateway", BodyMap{"client_msg_id": "id"}, &WxCommonResponse{}); err == nil {
+		t.Fatal("want error")
+	}
+	if n := atomic.SwapInt32(&calls, 0); n != 3 {
+		t.Fatalf("calls = %d, want 3", n)
+	}
+}
+
+func TestClientStream(t *testing.T) {
+	img := bytes.Repeat([]byte{0xff}, 6<<20) // 超过 EndBytes 的 5MB 限制
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"errcode":40007,"errmsg":"invalid media_id"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Header().Set("Content-Disposition", `attachment; filename="a.jpg"`)
+		w.Header().Set("Content-Length", strconv.Itoa(len(img)))
+		w.Write(img)
+	}))
+	defer srv.Close()
+
+	c := NewClient(StaticTokenSource("TOKEN"), WithBaseURL(srv.URL), WithTimeout(time.Second))
+	ctx := context.Background()
+
+	stream, err := c.DoRequestGetStream(ctx, "/media")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := io.ReadAll(stream)
+	stream.Close()
+	if err != nil || !bytes.Equal(bs, img) {
+		t.Fatalf("read %d bytes, err = %v", len(bs), err)
+	}
+	if stream.ContentType != "image/jpeg" || stream.ContentLength != int64(len(img)) || stream.Filename != "a.jpg" {
+		t.Fatalf("unexpected stream: %+v", stream)
+	}
+
+	var apiErr *APIError
+	if _, err := c.DoRequestPostStream(ctx, "/error", BodyMap{}); !errors.As(err, &apiErr) || apiErr.Code != 40007 {
+		t.Fatalf("DoRequestPostStream() = %v", err)
+	}
+}
+
+func TestReadImage(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 不带 Content-Type 时按内容判断
+		w.Header().Set("Content-Type", "application/octet-stream")
+		if r.URL.Path == "/wxa/getwxacodeunlimit" {
+			w.Write([]byte(`{"errcode":41030,"errmsg":"invalid page"}`))
+			return
+		}
+		w.Write(png)
+	}))
+	defer srv.Close()
+
+	c := NewClient(StaticTokenSource("TOKEN"), WithBaseURL(srv.URL))
+	ctx := context.Background()
+
+	stream, err := c.DoRequestPostStream(ctx, "/cgi-bin/wxaapp/createwxaqrcode", BodyMap{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := ReadImage(stream)
+	if err != nil || img.ContentType != "image/png" || !bytes.Equal(img.Data, png) {
+		t.Fatalf("ReadImage() = %+v, %v", img, err)
+	}
+
+	stream, err = c.DoRequestPostStream(ctx, "/wxa/getwxacodeunlimit", BodyMap{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var apiErr *APIError
+	if _, err := ReadImage(stream); !errors.As(err, &apiErr) || apiErr.Code != 41030 || apiErr.Product != ProductMini {
+		t.Fatalf("ReadImage() = %v", err)
+	}
+}
+
+func TestClientUploadFile(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 1<<20)...)
+	path := filepath.Join(t.TempDir(), "a.png")
+	if err := os.WriteFile(path, png, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("media")
+		if err != nil {
+			// 客户端中断上传时读取失败，由客户端的断言检查
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		bs, _ := io.ReadAll(file)
+		if header.Filename != "a.png" || header.Header.Get("Content-Type") != "image/png" || !bytes.Equal(bs, png) {
+			t.Errorf("unexpected file %s %s %d bytes", header.Filename, header.Header.Get("Content-Type"), len(bs))
+		}
+		if r.FormValue("type") != "image" {
+			t.Errorf("type = %s", r.FormValue("type"))
+		}
+		w.Write([]byte(`{"errcode":0,"content_length":` + strconv.FormatInt(r.ContentLength, 10) + `}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(StaticTokenSource("TOKEN"), WithBaseURL(srv.URL))
+	ctx := context.Background()
+
+	var rsp struct {
+		ContentLength int64 `json:"content_length"`
+	}
+	if err := c.DoUploadFile(ctx, "/cgi-bin/media/upload", BodyMap{"type": "image"}.SetFormFile("media", util.NewFileFromPath(path)), &rsp); err != nil {
+		t.Fatal(err)
+	}
+	if rsp.ContentLength <= int64(len(png)) {
+		t.Fatalf("ContentLength = %d", rsp.ContentLength)
+	}
+
+	// 大小未知时分块发送，不能 Seek 的 Reader 只能上传一次
+	file := util.NewFileFromReader("a.png", io.MultiReader(bytes.NewReader(png)), 0)
+	if err := c.DoUploadFile(ctx, "/cgi-bin/media/upload", BodyMap{"type": "image"}.SetFormFile("media", file), &rsp); err != nil {
+		t.Fatal(err)
+	}
+	if rsp.ContentLength != -1 {
+		t.Fatalf("ContentLength = %d", rsp.ContentLength)
+	}
+	if err := c.DoUploadFile(ctx, "/cgi-bin/media/upload", BodyMap{"type": "image"}.SetFormFile("media", file), &rsp); !errors.Is(err, util.ErrFileConsumed) {
+		t.Fatalf("DoUploadFile() = %v", err)
+	}
+	// Size 与实际内容不一致
+	file = util.NewFileFromReader("a.png", io.MultiReader(bytes.NewReader(png)), int64(len(png))-1)
+	if err := c.DoUploadFile(ctx, "/cgi-bin/media/upload", BodyMap{"type": "image"}.SetFormFile("media", file), &rsp); !errors.Is(err, util.ErrFileSizeMismatch) {
+		t.Fatalf("DoUploadFile() = %v", err)
+	}
+}
+
+func TestClientUploadFileOnce(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte(`{"errcode":40001,"errmsg":"invalid credential"}`))
+	}))
+	defer srv.Close()
+
+	m := NewTokenManager("wx_upload_once", "secret", NewMemoryTokenStore())
+	m.SetAccessToken(context.Background(), WxAccessToken{AccessToken: "TOKEN", ExpiresTime: time.Now().Unix() + 7200})
+	c := NewClient(m, WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, RetryNonIdempotent: true}))
+
+	// 不能 Seek 的 Reader 不刷新 access_token 重放，返回真实的错误
+	file := util.NewFileFromReader("a.txt", io.MultiReader(strings.NewReader("hello")), 0)
+	err := c.DoUploadFile(context.Background(), "/cgi-bin/media/upload", BodyMap{"type": "image"}.SetFormFile("media", file), &WxCommonResponse{})
+	if !IsTokenExpired(err) {
+		t.Fatalf("DoUploadFile() = %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("calls = %d, want 1", n)
+	}
+}
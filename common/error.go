@@ -1,146 +1,199 @@
 package common
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
 
+//go:generate go run ../internal/errcodegen -in ../internal/errcodegen/data -out error_catalog.go
+
+// Locale 错误码说明的语言
+type Locale string
+
+const (
+	LocaleZhCN Locale = "zh_CN" // 简体中文，默认
+	LocaleEnUS Locale = "en_US" // 英文
+)
+
+// Product 错误码所属的产品线，同一错误码在不同产品线的含义可能不同
+type Product string
+
+const (
+	ProductGeneral Product = "general" // 全局返回码
+	ProductMini    Product = "mini"    // 小程序
+	ProductOCR     Product = "ocr"     // 图像处理、OCR
+	ProductOpen    Product = "open"    // 开放平台第三方平台
+)
+
+type errText struct {
+	zh, en string
+}
+
+var errLocale atomic.Value
+
+// SetErrorLocale 设置 APIError 中错误码说明的语言，默认简体中文
+func SetErrorLocale(locale Locale) {
+	errLocale.Store(locale)
+}
+
+func currentErrorLocale() Locale {
+	if locale, ok := errLocale.Load().(Locale); ok {
+		return locale
+	}
+	return LocaleZhCN
+}
+
+// ErrorDescription 错误码说明，优先使用 product 产品线的说明，没有时使用全局返回码的说明，都没有时返回空
+func ErrorDescription(product Product, code int64, locale Locale) string {
+	text, ok := errCatalog[product][code]
+	if !ok {
+		if text, ok = errCatalog[ProductGeneral][code]; !ok {
+			return ""
+		}
+	}
+	if locale == LocaleEnUS {
+		return text.en
+	}
+	return text.zh
+}
+
+// 根据接口地址判断所属产品线
+func productOf(uri string) Product {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ProductGeneral
+	}
+	path := u.Path
+	switch {
+	case strings.HasPrefix(path, "/cv/"):
+		return ProductOCR
+	case strings.HasPrefix(path, "/cgi-bin/component/"), strings.HasPrefix(path, "/cgi-bin/open/"):
+		return ProductOpen
+	case strings.HasPrefix(path, "/wxa/"), strings.HasPrefix(path, "/wxaapi/"),
+		strings.HasPrefix(path, "/sns/jscode2session"), strings.HasPrefix(path, "/cgi-bin/wxaapp/"),
+		strings.HasPrefix(path, "/cgi-bin/message/wxopen/"), path == "/cgi-bin/message/subscribe/send":
+		return ProductMini
+	}
+	return ProductGeneral
+}
+
+// APIError 接口返回的错误，可通过 errors.As 获取错误码
+type APIError struct {
+	Code        int64   // errcode
+	Message     string  // errmsg
+	Description string  // 错误码说明，未收录的错误码为空
+	URL         string  // 请求地址，access_token 等敏感参数已隐藏
+	Rid         string  // errmsg 中的 rid，可在微信后台 rid 查询工具中定位问题
+	Product     Product // 错误码所属的产品线，根据请求地址判断
+}
+
+func (e *APIError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("ErrCode(%d),ErrMsg(%s),%s", e.Code, e.Message, e.Description)
+	}
+	return fmt.Sprintf("ErrCode(%d),ErrMsg(%s)", e.Code, e.Message)
+}
+
+// Localize 指定语言的错误码说明
+func (e *APIError) Localize(locale Locale) string {
+	return ErrorDescription(e.Product, e.Code, locale)
+}
+
+// NewAPIError 根据 errcode、errmsg 创建错误，错误码说明使用全局返回码
+func NewAPIError(code int64, msg string) *APIError {
+	return newAPIError(ProductGeneral, code, msg)
+}
+
+func newAPIError(product Product, code int64, msg string) *APIError {
+	e := &APIError{
+		Code:        code,
+		Message:     msg,
+		Description: ErrorDescription(product, code, currentErrorLocale()),
+		Product:     product,
+	}
+	if i := strings.LastIndex(msg, "rid: "); i >= 0 {
+		e.Rid = strings.TrimSpace(msg[i+len("rid: "):])
+	}
+	return e
+}
+
+// ToError 根据 errcode、errmsg 创建错误，返回 *APIError
 func ToError(code int64, msg string) error {
+	return NewAPIError(code, msg)
+}
 
-	errInfo := map[int64]string{
-		-1:    "系统繁忙，此时请开发者稍候再试",
-		0:     "请求成功",
-		40001: "AppSecret错误，或者 access_token 无效",
-		40002: "不合法的凭证类型",
-		40003: "不合法的OpenID",
-		40004: "不合法的媒体文件类型",
-		40005: "不合法的文件类型",
-		40006: "不合法的文件大小",
-		40007: "不合法的媒体文件id",
-		40008: "不合法的消息类型",
-		40009: "不合法的图片文件大小",
-		40010: "不合法的语音文件大小",
-		40011: "不合法的视频文件大小",
-		40012: "不合法的缩略图文件大小",
-		40013: "不合法的 appid",
-		40014: "不合法的 access_token",
-		40015: "不合法的菜单类型",
-		40016: "不合法的按钮个数",
-		40017: "不合法的按钮个数",
-		40018: "不合法的按钮名字长度",
-		40019: "不合法的按钮 KEY 长度",
-		40020: "不合法的按钮 URL 长度",
-		40021: "不合法的菜单版本号",
-		40022: "不合法的子菜单级数",
-		40023: "不合法的子菜单按钮个数",
-		40024: "不合法的子菜单按钮类型",
-		40025: "不合法的子菜单按钮名字长度",
-		40026: "不合法的子菜单按钮 KEY 长度",
-		40027: "不合法的子菜单按钮 URL 长度",
-		40028: "不合法的自定义菜单使用用户",
-		40029: "不合法的 oauth_code",
-		40030: "不合法的 refresh_token",
-		40031: "不合法的 openid 列表",
-		40032: "每次传入的 openid 列表个数不能超过50个",
-		40033: "不合法的请求字符，不能包含 \\uxxxx 格式的字符",
-		40035: "不合法的参数",
-		40038: "不合法的请求格式",
-		40039: "不合法的 URL 长度",
-		40050: "不合法的分组 id",
-		40051: "分组名字不合法",
-		40060: "删除单篇图文时，指定的 article_idx 不合法",
-		40117: "分组名字不合法",
-		40118: "media_id 大小不合法",
-		40119: "button 类型错误",
-		40120: "button 类型错误",
-		40121: "不合法的 media_id 类型",
-		40132: "微信号不合法",
-		40137: "不支持的图片格式",
-		40155: "请勿添加其他公众号的主页链接",
-		41001: "缺少 access_token 参数",
-		41002: "缺少 appid 参数",
-		41003: "缺少 refresh_token 参数",
-		41004: "缺少 secret 参数",
-		41005: "缺少多媒体文件数据",
-		41006: "缺少 media_id 参数",
-		41007: "缺少子菜单数据",
-		41008: "缺少 oauth code",
-		41009: "缺少 openid",
-		42001: "access_token 超时，请检查 access_token 的有效期，请参考基础支持 - 获取 access_token 中，对 access_token 的详细机制说明",
-		42002: "refresh_token 超时",
-		42003: "oauth_code 超时",
-		42007: "用户修改微信密码， accesstoken 和 refreshtoken 失效，需要重新授权",
-		43001: "需要 GET 请求",
-		43002: "需要 POST 请求",
-		43003: "需要 HTTPS 请求",
-		43004: "需要接收者关注",
-		43005: "需要好友关系",
-		43019: "需要将接收者从黑名单中移除",
-		44001: "多媒体文件为空",
-		44002: "POST 的数据包为空",
-		44003: "图文消息内容为空",
-		44004: "文本消息内容为空",
-		45001: "多媒体文件大小超过限制",
-		45002: "消息内容超过限制",
-		45003: "标题字段超过限制",
-		45004: "描述字段超过限制",
-		45005: "链接字段超过限制",
-		45006: "图片链接字段超过限制",
-		45007: "语音播放时间超过限制",
-		45008: "图文消息超过限制",
-		45009: "没有剩余的调用次数",
-		45010: "创建菜单个数超过限制",
-		45011: "API 调用太频繁，请稍候再试",
-		45015: "回复时间超过限制",
-		45016: "系统分组，不允许修改",
-		45017: "分组名字过长",
-		45018: "分组数量超过上限",
-		45047: "客服接口下行条数超过上限",
-		45059: "有粉丝身上的标签数已经超过限制，即超过20个",
-		45159: "非法的标签",
-		46001: "不存在媒体数据",
-		46002: "不存在的菜单版本",
-		46003: "不存在的菜单数据",
-		46004: "不存在的用户",
-		47001: "解析 JSON/XML 内容错误",
-		48001: "api 功能未授权",
-		48002: "粉丝拒收消息（粉丝在公众号选项中，关闭了 '接收消息' ）",
-		48004: "api 接口被封禁",
-		48005: "api 禁止删除被自动回复和自定义菜单引用的素材",
-		48006: "api 禁止清零调用次数，因为清零次数达到上限",
-		48008: "没有该类型消息的发送权限",
-		49003: "传入的 openid 不属于此AppID",
-		50001: "用户未授权该 api",
-		50002: "用户受限，可能是违规后接口被封禁",
-		50005: "用户未关注公众号",
-		65400: "API不可用，即没有开通/升级到新版客服功能",
-		65401: "无效客服帐号",
-		65403: "客服昵称不合法",
-		65404: "客服帐号不合法",
-		65405: "帐号数目已达到上限，不能继续添加",
-		65406: "已经存在的客服帐号",
-		65407: "邀请对象已经是该公众号客服",
-		65408: "本公众号已经有一个邀请给该微信",
-		65409: "无效的微信号",
-		65410: "邀请对象绑定公众号客服数达到上限（目前每个微信号可以绑定5个公众号客服帐号）",
-		65411: "该帐号已经有一个等待确认的邀请，不能重复邀请",
-		65412: "该帐号已经绑定微信号，不能进行邀请",
-		65413: "不存在对应用户的会话信息",
-		65414: "粉丝正在被其他客服接待",
-		65415: "指定的客服不在线",
-		65416: "查询参数不合法",
-		65417: "查询时间段超出限制",
-		88000: "没有留言权限",
-		88001: "该图文不存在",
-		88002: "文章存在敏感信息",
-		88003: "精选评论数已达上限",
-		88004: "已被用户删除，无法精选",
-		88005: "已经回复过了",
-		88007: "回复超过长度限制或为0",
-		88008: "该评论不存在",
-		88010: "获取评论数目不合法 cout <= 0 or count > 50",
-	}
-
-	if value, ok := errInfo[code]; ok {
-		return fmt.Errorf("%d %s", code, value)
-	} else {
-		return fmt.Errorf("%d %s", code, msg)
+// IsTokenExpired access_token 无效或已过期，刷新 access_token 后可以重试
+func IsTokenExpired(err error) bool {
+	return hasErrCode(err, 40001, 40014, 42001)
+}
+
+// IsRateLimited 调用太频繁或超过调用次数限制，包括客户端限流 ErrRateLimited
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited) || hasErrCode(err, 45009, 45011, 45047)
+}
+
+// IsUserRefused 用户拒收、取消订阅或未关注，不能向该用户发送消息
+func IsUserRefused(err error) bool {
+	return hasErrCode(err, 43004, 43101, 48002)
+}
+
+func hasErrCode(err error, codes ...int64) bool {
+	var e *APIError
+	if !errors.As(err, &e) {
+		return false
+	}
+	for _, code := range codes {
+		if e.Code == code {
+			return true
+		}
 	}
+	return false
 }
+
+// RedactURL 隐藏 uri 中的 access_token、secret 等参数，用于记录日志
+func RedactURL(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	query := u.Query()
+	for key := range query {
+		if secretKeys[key] {
+			query.Set(key, "***")
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// RedactError 隐藏错误中请求地址的 access_token 等参数
+// http.Client 返回的 *url.Error 包含完整的请求地址，记录日志、返回给调用方之前需要处理
+func RedactError(err error) error {
+	var urlErr *url.Error
+	if err == nil || !errors.As(err, &urlErr) {
+		return err
+	}
+	redacted := RedactURL(urlErr.URL)
+	if redacted == urlErr.URL {
+		return err
+	}
+	if err == error(urlErr) {
+		return &url.Error{Op: urlErr.Op, URL: redacted, Err: urlErr.Err}
+	}
+	// *url.Error 被包装过时替换错误信息中的地址
+	return &redactedError{
+		msg: strings.ReplaceAll(err.Error(), urlErr.URL, redacted),
+		err: &url.Error{Op: urlErr.Op, URL: redacted, Err: urlErr.Err},
+	}
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
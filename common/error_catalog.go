@@ -0,0 +1,227 @@
+// Code generated by internal/errcodegen; DO NOT EDIT.
+
+package common
+
+var errCatalog = map[Product]map[int64]errText{
+	ProductGeneral: {
+		-1:    {"系统繁忙，此时请开发者稍候再试", "System busy, please try again later"},
+		0:     {"请求成功", "Request succeeded"},
+		40001: {"AppSecret错误，或者 access_token 无效", "Invalid AppSecret or access_token"},
+		40002: {"不合法的凭证类型", "Invalid credential type"},
+		40003: {"不合法的OpenID", "Invalid OpenID"},
+		40004: {"不合法的媒体文件类型", "Invalid media file type"},
+		40005: {"不合法的文件类型", "Invalid file type"},
+		40006: {"不合法的文件大小", "Invalid file size"},
+		40007: {"不合法的媒体文件id", "Invalid media_id"},
+		40008: {"不合法的消息类型", "Invalid message type"},
+		40009: {"不合法的图片文件大小", "Invalid image file size"},
+		40010: {"不合法的语音文件大小", "Invalid voice file size"},
+		40011: {"不合法的视频文件大小", "Invalid video file size"},
+		40012: {"不合法的缩略图文件大小", "Invalid thumbnail file size"},
+		40013: {"不合法的 appid", "Invalid appid"},
+		40014: {"不合法的 access_token", "Invalid access_token"},
+		40015: {"不合法的菜单类型", "Invalid menu type"},
+		40016: {"不合法的按钮个数", "Invalid number of buttons"},
+		40017: {"不合法的按钮个数", "Invalid number of buttons"},
+		40018: {"不合法的按钮名字长度", "Invalid button name length"},
+		40019: {"不合法的按钮 KEY 长度", "Invalid button KEY length"},
+		40020: {"不合法的按钮 URL 长度", "Invalid button URL length"},
+		40021: {"不合法的菜单版本号", "Invalid menu version"},
+		40022: {"不合法的子菜单级数", "Invalid sub-menu level"},
+		40023: {"不合法的子菜单按钮个数", "Invalid number of sub-menu buttons"},
+		40024: {"不合法的子菜单按钮类型", "Invalid sub-menu button type"},
+		40025: {"不合法的子菜单按钮名字长度", "Invalid sub-menu button name length"},
+		40026: {"不合法的子菜单按钮 KEY 长度", "Invalid sub-menu button KEY length"},
+		40027: {"不合法的子菜单按钮 URL 长度", "Invalid sub-menu button URL length"},
+		40028: {"不合法的自定义菜单使用用户", "Invalid custom menu user"},
+		40029: {"不合法的 oauth_code", "Invalid oauth_code"},
+		40030: {"不合法的 refresh_token", "Invalid refresh_token"},
+		40031: {"不合法的 openid 列表", "Invalid openid list"},
+		40032: {"每次传入的 openid 列表个数不能超过50个", "The openid list cannot contain more than 50 items"},
+		40033: {"不合法的请求字符，不能包含 \\uxxxx 格式的字符", "Invalid request characters, \\uxxxx characters are not allowed"},
+		40035: {"不合法的参数", "Invalid parameter"},
+		40038: {"不合法的请求格式", "Invalid request format"},
+		40039: {"不合法的 URL 长度", "Invalid URL length"},
+		40050: {"不合法的分组 id", "Invalid group id"},
+		40051: {"分组名字不合法", "Invalid group name"},
+		40060: {"删除单篇图文时，指定的 article_idx 不合法", "Invalid article_idx when deleting a single article"},
+		40117: {"分组名字不合法", "Invalid group name"},
+		40118: {"media_id 大小不合法", "Invalid media_id size"},
+		40119: {"button 类型错误", "Invalid button type"},
+		40120: {"button 类型错误", "Invalid button type"},
+		40121: {"不合法的 media_id 类型", "Invalid media_id type"},
+		40132: {"微信号不合法", "Invalid WeChat ID"},
+		40137: {"不支持的图片格式", "Unsupported image format"},
+		40155: {"请勿添加其他公众号的主页链接", "Links to other official accounts' home pages are not allowed"},
+		40164: {"调用接口的IP地址不在白名单中", "The caller IP is not in the whitelist"},
+		41001: {"缺少 access_token 参数", "Missing access_token"},
+		41002: {"缺少 appid 参数", "Missing appid"},
+		41003: {"缺少 refresh_token 参数", "Missing refresh_token"},
+		41004: {"缺少 secret 参数", "Missing secret"},
+		41005: {"缺少多媒体文件数据", "Missing media file data"},
+		41006: {"缺少 media_id 参数", "Missing media_id"},
+		41007: {"缺少子菜单数据", "Missing sub-menu data"},
+		41008: {"缺少 oauth code", "Missing oauth code"},
+		41009: {"缺少 openid", "Missing openid"},
+		42001: {"access_token 超时，请检查 access_token 的有效期，请参考基础支持 - 获取 access_token 中，对 access_token 的详细机制说明", "access_token expired, please check its validity period"},
+		42002: {"refresh_token 超时", "refresh_token expired"},
+		42003: {"oauth_code 超时", "oauth_code expired"},
+		42007: {"用户修改微信密码， accesstoken 和 refreshtoken 失效，需要重新授权", "The user changed the WeChat password, access_token and refresh_token are invalid and re-authorization is required"},
+		43001: {"需要 GET 请求", "GET request required"},
+		43002: {"需要 POST 请求", "POST request required"},
+		43003: {"需要 HTTPS 请求", "HTTPS request required"},
+		43004: {"需要接收者关注", "The recipient must follow the account"},
+		43005: {"需要好友关系", "Friendship required"},
+		43019: {"需要将接收者从黑名单中移除", "The recipient must be removed from the blacklist"},
+		43101: {"用户拒绝接受消息，如果用户之前曾经订阅过，则表示用户取消了订阅关系", "The user refused to receive messages or has cancelled the subscription"},
+		44001: {"多媒体文件为空", "Media file is empty"},
+		44002: {"POST 的数据包为空", "POST body is empty"},
+		44003: {"图文消息内容为空", "News message content is empty"},
+		44004: {"文本消息内容为空", "Text message content is empty"},
+		45001: {"多媒体文件大小超过限制", "Media file size exceeds the limit"},
+		45002: {"消息内容超过限制", "Message content exceeds the limit"},
+		45003: {"标题字段超过限制", "Title exceeds the limit"},
+		45004: {"描述字段超过限制", "Description exceeds the limit"},
+		45005: {"链接字段超过限制", "Link exceeds the limit"},
+		45006: {"图片链接字段超过限制", "Image link exceeds the limit"},
+		45007: {"语音播放时间超过限制", "Voice duration exceeds the limit"},
+		45008: {"图文消息超过限制", "Too many articles in the news message"},
+		45009: {"没有剩余的调用次数", "API daily quota exhausted"},
+		45010: {"创建菜单个数超过限制", "Too many menus"},
+		45011: {"API 调用太频繁，请稍候再试", "API called too frequently, please try again later"},
+		45015: {"回复时间超过限制", "Reply time limit exceeded"},
+		45016: {"系统分组，不允许修改", "System groups cannot be modified"},
+		45017: {"分组名字过长", "Group name too long"},
+		45018: {"分组数量超过上限", "Too many groups"},
+		45047: {"客服接口下行条数超过上限", "Customer service message limit exceeded"},
+		45059: {"有粉丝身上的标签数已经超过限制，即超过20个", "A follower already has more than 20 tags"},
+		45159: {"非法的标签", "Invalid tag"},
+		46001: {"不存在媒体数据", "Media data does not exist"},
+		46002: {"不存在的菜单版本", "Menu version does not exist"},
+		46003: {"不存在的菜单数据", "Menu data does not exist"},
+		46004: {"不存在的用户", "User does not exist"},
+		47001: {"解析 JSON/XML 内容错误", "Failed to parse JSON/XML content"},
+		47003: {"参数值不符合限制要求", "Parameter value does not meet the requirements"},
+		48001: {"api 功能未授权", "API unauthorized"},
+		48002: {"粉丝拒收消息（粉丝在公众号选项中，关闭了 '接收消息' ）", "The follower has turned off \"Receive Messages\""},
+		48004: {"api 接口被封禁", "API banned"},
+		48005: {"api 禁止删除被自动回复和自定义菜单引用的素材", "Materials referenced by auto-reply or custom menu cannot be deleted"},
+		48006: {"api 禁止清零调用次数，因为清零次数达到上限", "Quota reset limit reached"},
+		48008: {"没有该类型消息的发送权限", "No permission to send this type of message"},
+		49003: {"传入的 openid 不属于此AppID", "The openid does not belong to this AppID"},
+		50001: {"用户未授权该 api", "The user has not authorized this API"},
+		50002: {"用户受限，可能是违规后接口被封禁", "The user is restricted, possibly banned for violations"},
+		50005: {"用户未关注公众号", "The user does not follow the official account"},
+		61451: {"参数错误", "Invalid parameter"},
+		61452: {"无效客服账号", "Invalid customer service account"},
+		61453: {"客服帐号已存在", "Customer service account already exists"},
+		65400: {"API不可用，即没有开通/升级到新版客服功能", "API unavailable, the new customer service feature is not enabled"},
+		65401: {"无效客服帐号", "Invalid customer service account"},
+		65403: {"客服昵称不合法", "Invalid customer service nickname"},
+		65404: {"客服帐号不合法", "Invalid customer service account"},
+		65405: {"帐号数目已达到上限，不能继续添加", "Customer service account limit reached"},
+		65406: {"已经存在的客服帐号", "Customer service account already exists"},
+		65407: {"邀请对象已经是该公众号客服", "The invitee is already a customer service agent of this account"},
+		65408: {"本公众号已经有一个邀请给该微信", "An invitation to this WeChat user is already pending"},
+		65409: {"无效的微信号", "Invalid WeChat ID"},
+		65410: {"邀请对象绑定公众号客服数达到上限（目前每个微信号可以绑定5个公众号客服帐号）", "The invitee has reached the limit of bound customer service accounts (5)"},
+		65411: {"该帐号已经有一个等待确认的邀请，不能重复邀请", "This account already has a pending invitation"},
+		65412: {"该帐号已经绑定微信号，不能进行邀请", "This account is already bound to a WeChat ID"},
+		65413: {"不存在对应用户的会话信息", "No session exists for this user"},
+		65414: {"粉丝正在被其他客服接待", "The follower is being served by another agent"},
+		65415: {"指定的客服不在线", "The specified agent is offline"},
+		65416: {"查询参数不合法", "Invalid query parameters"},
+		65417: {"查询时间段超出限制", "Query time range exceeds the limit"},
+		88000: {"没有留言权限", "No comment permission"},
+		88001: {"该图文不存在", "The article does not exist"},
+		88002: {"文章存在敏感信息", "The article contains sensitive content"},
+		88003: {"精选评论数已达上限", "Featured comment limit reached"},
+		88004: {"已被用户删除，无法精选", "The comment was deleted by the user and cannot be featured"},
+		88005: {"已经回复过了", "Already replied"},
+		88007: {"回复超过长度限制或为0", "Reply length is 0 or exceeds the limit"},
+		88008: {"该评论不存在", "The comment does not exist"},
+		88010: {"获取评论数目不合法 cout <= 0 or count > 50", "Invalid comment count, count must be between 1 and 50"},
+	},
+	ProductMini: {
+		40029:   {"code 无效", "Invalid code"},
+		40037:   {"template_id 不正确", "Invalid template_id"},
+		40097:   {"参数错误", "Invalid parameter"},
+		40163:   {"code 已被使用", "The code has been used"},
+		40165:   {"参数 path 填写错误", "Invalid path"},
+		40169:   {"scene 不合法", "Invalid scene"},
+		40212:   {"参数 query 填写错误", "Invalid query"},
+		40226:   {"高风险等级用户，小程序登录拦截", "High-risk user, login blocked"},
+		41028:   {"form_id 不正确，或者过期", "Invalid or expired form_id"},
+		41029:   {"form_id 已被使用", "The form_id has been used"},
+		41030:   {"page 路径不正确，需要保证在现网版本小程序中存在", "Invalid page, it must exist in the released version"},
+		44990:   {"生成 Scheme/URL Link 频率过快（超过100次/秒）", "Scheme/URL Link generated too frequently (over 100 per second)"},
+		45009:   {"单天生成 Scheme/URL Link 或小程序码数量超过上限", "Daily Scheme/URL Link or QR code limit exceeded"},
+		45011:   {"频率限制，每个用户每分钟100次", "Rate limited, 100 calls per user per minute"},
+		47003:   {"模板参数不准确，可能为空或者不满足规则", "Invalid template data, it may be empty or break the rules"},
+		85079:   {"小程序没有线上版本，不能进行灰度", "The mini program has no released version"},
+		85096:   {"scancode_time 为系统保留参数，不允许配置", "scancode_time is a reserved parameter"},
+		85400:   {"长期有效 Scheme/URL Link/Short Link 已达到生成上限（10万条）", "Permanent Scheme/URL Link/Short Link limit (100,000) reached"},
+		85401:   {"参数 expire_time 填写错误，时间间隔需大于1分钟且小于1年", "Invalid expire_time, the interval must be between 1 minute and 1 year"},
+		85402:   {"参数 env_version 填写错误", "Invalid env_version"},
+		87014:   {"内容含有违法违规内容", "The content contains illegal or sensitive information"},
+		300001:  {"禁止创建/更新商品或禁止编辑/更新房间", "Creating or updating goods or rooms is forbidden"},
+		300002:  {"名称长度不符合规则", "Invalid name length"},
+		300006:  {"图片上传失败", "Image upload failed"},
+		300022:  {"此房间号不存在", "The room does not exist"},
+		300023:  {"房间状态拦截，当前房间状态不允许此操作", "The current room status does not allow this operation"},
+		300024:  {"商品不存在", "The goods do not exist"},
+		300025:  {"商品审核未通过", "The goods failed review"},
+		300026:  {"房间商品数量已经满额", "The room has reached its goods limit"},
+		300027:  {"导入商品失败", "Failed to import goods"},
+		300028:  {"房间名称违规", "The room name violates the rules"},
+		300029:  {"主播昵称违规", "The anchor nickname violates the rules"},
+		300030:  {"主播微信号不合法", "Invalid anchor WeChat ID"},
+		300031:  {"直播间封面图不合规", "The room cover image violates the rules"},
+		300032:  {"直播间分享图违规", "The room share image violates the rules"},
+		300033:  {"添加商品超过直播间上限", "Too many goods added to the room"},
+		300034:  {"主播微信昵称长度不符合要求", "Invalid anchor nickname length"},
+		300035:  {"主播微信号不存在", "The anchor WeChat ID does not exist"},
+		300036:  {"主播微信号未实名认证", "The anchor WeChat ID is not real-name verified"},
+		9410000: {"直播间列表为空", "The live room list is empty"},
+	},
+	ProductOCR: {
+		101000: {"图片 URL 错误或拉取 URL 图像错误", "Invalid image URL or failed to download the image"},
+		101001: {"图片中无法找到证件", "No certificate found in the image"},
+		101002: {"图片数据无效", "Invalid image data"},
+	},
+	ProductOpen: {
+		61003: {"该账号未授权给第三方平台", "The account has not authorized the component"},
+		61004: {"当前调用接口的 IP 不在第三方平台白名单中", "The client IP is not in the component whitelist"},
+		61005: {"component_verify_ticket 已过期", "component_verify_ticket expired"},
+		61006: {"component_verify_ticket 无效", "Invalid component_verify_ticket"},
+		61007: {"授权方未授权第三方平台该权限集", "The authorizer has not granted this permission to the component"},
+		61008: {"component req key 重复", "Duplicate component request key"},
+		61009: {"授权码无效", "Invalid authorization code"},
+		61010: {"授权码已过期", "Authorization code expired"},
+		61011: {"无效的第三方平台", "Invalid component"},
+		61012: {"无效的选项名称", "Invalid option name"},
+		61013: {"无效的选项值", "Invalid option value"},
+		61014: {"第三方平台接口需要使用 component_access_token", "Component APIs require component_access_token"},
+		61015: {"非第三方平台接口需要使用授权方的 access_token", "Non-component APIs require the authorizer's access_token"},
+		61016: {"接口所属权限集需要授权方确认", "The API permission set must be confirmed by the authorizer"},
+		61017: {"接口所属权限集未授权", "The API permission set is not authorized"},
+		61018: {"权限集已确认", "Permission set already confirmed"},
+		61019: {"权限集无需确认", "Permission set does not need confirmation"},
+		61020: {"参数错误", "Invalid parameter"},
+		61021: {"无法确认", "Cannot confirm"},
+		61022: {"无法重新提交", "Cannot resubmit"},
+		61023: {"authorizer_refresh_token 无效", "Invalid authorizer_refresh_token"},
+		61024: {"第三方平台账号需要通过 api_component_token 获取令牌", "Component accounts must get tokens via api_component_token"},
+		61025: {"只读选项", "Read-only option"},
+		61026: {"注册被拒绝", "Registration denied"},
+		61027: {"注册次数超过限制", "Registration limit exceeded"},
+		61028: {"第三方平台未发布", "The component is not published"},
+		61029: {"第三方平台需要重新发布基础权限集", "The component must be republished with the base permission set"},
+		61030: {"不允许取消授权", "Cancelling authorization is not allowed"},
+		89000: {"该公众号/小程序已经绑定了开放平台帐号", "The account is already bound to an open platform account"},
+		89001: {"授权方与开放平台帐号主体不相同", "The authorizer and the open platform account have different owners"},
+		89002: {"该公众号/小程序未绑定微信开放平台帐号", "The account is not bound to an open platform account"},
+		89003: {"该开放平台帐号并非通过 api 创建，不允许操作", "The open platform account was not created via API"},
+		89004: {"该开放平台帐号所绑定的公众号/小程序已达上限", "The open platform account has reached its binding limit"},
+	},
+}
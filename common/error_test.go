@@ -0,0 +1,80 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	bs := []byte(`{"errcode":40001,"errmsg":"invalid credential, access_token is invalid or not latest rid: 6531ab12-1a2b3c4d-5e6f7a8b"}`)
+	err := checkResponse("https://api.weixin.qq.com/cgi-bin/menu/get?access_token=TOKEN", bs)
+	err = fmt.Errorf("do request: %w", err)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("errors.As(%v) = false", err)
+	}
+	if apiErr.Code != 40001 || apiErr.Rid != "6531ab12-1a2b3c4d-5e6f7a8b" || apiErr.Description == "" {
+		t.Fatalf("unexpected error: %+v", apiErr)
+	}
+	if apiErr.URL != "https://api.weixin.qq.com/cgi-bin/menu/get?access_token=%2A%2A%2A" {
+		t.Fatalf("access_token not redacted: %s", apiErr.URL)
+	}
+
+	if !IsTokenExpired(err) || IsRateLimited(err) || IsUserRefused(err) {
+		t.Fatalf("wrong category for %v", err)
+	}
+	if !IsRateLimited(ToError(45011, "api minute-quota reach limit")) {
+		t.Fatal("45011 should be rate limited")
+	}
+	if !IsUserRefused(ToError(43101, "user refuse to accept the msg")) {
+		t.Fatal("43101 should be user refused")
+	}
+	if IsTokenExpired(errors.New("ErrCode(40001)")) {
+		t.Fatal("plain error should not match")
+	}
+}
+
+func TestErrorCatalog(t *testing.T) {
+	err := checkResponse("https://api.weixin.qq.com/sns/jscode2session?appid=APPID&secret=SECRET", []byte(`{"errcode":40029,"errmsg":"invalid code"}`))
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("errors.As(%v) = false", err)
+	}
+	if apiErr.Product != ProductMini || apiErr.Description != "code 无效" {
+		t.Fatalf("unexpected error: %+v", apiErr)
+	}
+	if got := apiErr.Localize(LocaleEnUS); got != "Invalid code" {
+		t.Fatalf("Localize(en_US) = %q", got)
+	}
+	if got := ErrorDescription(ProductMini, 40001, LocaleEnUS); got != "Invalid AppSecret or access_token" {
+		t.Fatalf("fallback to general catalog: %q", got)
+	}
+	if got := ErrorDescription(ProductOCR, 101001, LocaleZhCN); got != "图片中无法找到证件" {
+		t.Fatalf("ocr catalog: %q", got)
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	urlErr := &url.Error{Op: "Get", URL: "https://api.weixin.qq.com/cgi-bin/menu/get?access_token=SECRET_TOKEN", Err: context.DeadlineExceeded}
+
+	for _, err := range []error{urlErr, fmt.Errorf("do request: %w", urlErr)} {
+		redacted := RedactError(err)
+		if strings.Contains(redacted.Error(), "SECRET_TOKEN") {
+			t.Fatalf("error leaks access_token: %v", redacted)
+		}
+		if !errors.Is(redacted, context.DeadlineExceeded) {
+			t.Fatalf("cause lost: %v", redacted)
+		}
+	}
+
+	plain := errors.New("plain")
+	if RedactError(plain) != plain || RedactError(nil) != nil {
+		t.Fatal("errors without url changed")
+	}
+}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/medreams/wechat/pkg/xhttp"
+)
+
+// ErrNotImage 接口返回的既不是图片也不是错误信息
+var ErrNotImage = errors.New("response is not an image")
+
+// Image 接口返回的图片，如小程序码
+type Image struct {
+	Data        []byte
+	ContentType string // 如 image/jpeg、image/png
+}
+
+// ReadImage 读取并关闭图片流，Content-Type 不是图片时按内容判断，返回 json 错误信息时返回 *APIError
+func ReadImage(stream *Stream) (*Image, error) {
+	defer stream.Close()
+
+	bs, err := io.ReadAll(io.LimitReader(stream, xhttp.MaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(bs)) > xhttp.MaxBodySize {
+		return nil, fmt.Errorf("%w: more than %d bytes", xhttp.ErrBodyTooLarge, xhttp.MaxBodySize)
+	}
+
+	contentType, _, _ := mime.ParseMediaType(stream.ContentType)
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = http.DetectContentType(bs)
+	}
+	if strings.HasPrefix(contentType, "image/") {
+		return &Image{Data: bs, ContentType: contentType}, nil
+	}
+
+	if err := checkResponse(stream.uri, bs); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: Content-Type(%s)", ErrNotImage, stream.ContentType)
+}
@@ -0,0 +1,160 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/medreams/wechat/pkg/util"
+	"github.com/medreams/wechat/pkg/xhttp"
+)
+
+// Request 发送给微信接口的请求，拦截器可以修改
+type Request struct {
+	AppId     string // 发起请求的账号，未设置 WithAppId 时为空
+	Method    string
+	URL       string                 // 完整的请求地址，包含 access_token，记录日志时使用 RedactURL 隐藏
+	Header    http.Header            // 请求头
+	Body      map[string]interface{} // POST 请求体，上传文件时为表单字段，GET 请求为 nil
+	Multipart bool                   // 是否为 multipart/form-data 上传文件
+	Stream    bool                   // 是否以流的方式读取文件等二进制响应
+}
+
+// Response 微信接口返回的原始响应
+type Response struct {
+	StatusCode    int
+	Header        http.Header
+	Body          []byte        // 响应内容，Stream 不为 nil 时为空
+	Stream        io.ReadCloser // Request.Stream 为 true 且返回的不是 json 时为未读取的响应，由调用方关闭
+	ContentLength int64         // 响应长度，未知时为 -1
+	Latency       time.Duration // 本次 HTTP 请求耗时，不包含拦截器的耗时；Stream 不为 nil 时不包含读取响应的耗时
+}
+
+// RoundTripFunc 发送一次 HTTP 请求
+type RoundTripFunc func(ctx context.Context, req *Request) (*Response, error)
+
+// Interceptor 请求拦截器，调用 next 继续发送请求，可用于日志、监控、链路追踪等
+// 每次 HTTP 请求（包括重试、切换域名）都会经过拦截器
+type Interceptor func(ctx context.Context, req *Request, next RoundTripFunc) (*Response, error)
+
+// ChainInterceptors 将拦截器按顺序包装在 rt 外层，第一个拦截器最先执行
+func ChainInterceptors(rt RoundTripFunc, interceptors ...Interceptor) RoundTripFunc {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], rt
+		rt = func(ctx context.Context, req *Request) (*Response, error) {
+			return interceptor(ctx, req, next)
+		}
+	}
+	return rt
+}
+
+// LoggingInterceptor 每次请求输出一条 Info 日志，包含方法、地址、状态码、errcode 和耗时，失败时输出 Warn 日志
+// 请求体、响应体只在 Debug 级别输出；地址中的 access_token、请求体和响应体中的 secret、session_key、手机号等已隐藏
+func LoggingInterceptor(logger *slog.Logger) Interceptor {
+	return func(ctx context.Context, req *Request, next RoundTripFunc) (*Response, error) {
+		attrs := []slog.Attr{
+			slog.String("method", req.Method),
+			slog.String("url", RedactURL(req.URL)),
+		}
+		if req.AppId != "" {
+			attrs = append(attrs, slog.String("appid", req.AppId))
+		}
+		if req.Body != nil && logger.Enabled(ctx, slog.LevelDebug) {
+			logger.LogAttrs(ctx, slog.LevelDebug, "wechat request", append(attrs, slog.Any("body", MaskBody(req.Body)))...)
+		}
+
+		res, err := next(ctx, req)
+		if err != nil {
+			logger.LogAttrs(ctx, slog.LevelWarn, "wechat request failed", append(attrs, slog.Any("error", RedactError(err)))...)
+			return res, err
+		}
+
+		attrs = append(attrs, slog.Int("status", res.StatusCode), slog.Duration("latency", res.Latency))
+		level := slog.LevelInfo
+		msg := &WxCommonResponse{}
+		if json.Unmarshal(res.Body, msg) == nil && msg.ErrCode != 0 {
+			level = slog.LevelWarn
+			attrs = append(attrs, slog.Int64("errcode", msg.ErrCode), slog.String("errmsg", msg.ErrMsg))
+		} else if res.StatusCode != http.StatusOK {
+			level = slog.LevelWarn
+		}
+		if logger.Enabled(ctx, slog.LevelDebug) {
+			attrs = append(attrs, slog.String("response", MaskJSON(res.Body)))
+		}
+		logger.LogAttrs(ctx, level, "wechat response", attrs...)
+		return res, err
+	}
+}
+
+// 使用 hc 发送请求，hc 为 nil 时使用 xhttp 默认的 http.Client
+func httpRoundTrip(hc *http.Client) RoundTripFunc {
+	return func(ctx context.Context, req *Request) (*Response, error) {
+		httpClient := xhttp.NewClient()
+		if hc != nil {
+			httpClient.HttpClient = hc
+		}
+		if req.Header != nil {
+			httpClient.Header = req.Header
+		}
+
+		switch {
+		case req.Multipart:
+			httpClient.Type(xhttp.TypeMultipartFormData).Post(req.URL).SendMultipartBodyMap(req.Body)
+		case req.Method == http.MethodGet:
+			httpClient.Get(req.URL)
+		case req.Method == http.MethodPost:
+			httpClient.Post(req.URL).SendBodyMap(req.Body)
+		default:
+			return nil, fmt.Errorf("unsupported method %s", req.Method)
+		}
+
+		start := time.Now()
+		if !req.Stream {
+			res, bs, err := httpClient.EndBytes(ctx)
+			if err != nil {
+				return nil, RedactError(err)
+			}
+			return &Response{StatusCode: res.StatusCode, Header: res.Header, Body: bs, ContentLength: res.ContentLength, Latency: time.Since(start)}, nil
+		}
+
+		res, err := httpClient.EndStream(ctx)
+		if err != nil {
+			return nil, RedactError(err)
+		}
+		rsp := &Response{StatusCode: res.StatusCode, Header: res.Header, ContentLength: res.ContentLength, Latency: time.Since(start)}
+		if res.StatusCode == http.StatusOK && !isJSONResponse(res.Header) {
+			rsp.Stream = res.Body
+			return rsp, nil
+		}
+
+		// 错误信息以 json 返回，读取后检查 errcode
+		defer res.Body.Close()
+		if rsp.Body, err = io.ReadAll(io.LimitReader(res.Body, xhttp.MaxBodySize)); err != nil {
+			return nil, err
+		}
+		rsp.Latency = time.Since(start)
+		return rsp, nil
+	}
+}
+
+// 微信接口出错时返回 json，部分接口的 Content-Type 为 text/plain
+func isJSONResponse(header http.Header) bool {
+	contentType := header.Get("Content-Type")
+	return strings.Contains(contentType, "json") || strings.HasPrefix(contentType, "text/plain")
+}
+
+func newRequest(method, uri string, body map[string]interface{}) *Request {
+	header := make(http.Header)
+	header.Add(xhttp.HeaderRequestID, fmt.Sprintf("%s-%d", util.RandomString(21), time.Now().Unix()))
+	return &Request{
+		Method: method,
+		URL:    uri,
+		Header: header,
+		Body:   body,
+	}
+}
@@ -0,0 +1,143 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/medreams/wechat/pkg/util"
+)
+
+// Logger 日志接口，兼容标准库 *log.Logger；需要日志级别时使用 WithSlog
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// 默认不输出日志
+var discardLogger = slog.New(discardHandler{})
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// 将 Printf 日志适配为 slog.Handler，只输出 Info 及以上级别
+type printfHandler struct {
+	logger Logger
+	attrs  []slog.Attr
+}
+
+func (h *printfHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo
+}
+
+func (h *printfHandler) Handle(_ context.Context, r slog.Record) error {
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "[%s] %s", r.Level, r.Message)
+	write := func(a slog.Attr) bool {
+		fmt.Fprintf(buf, " %s=%v", a.Key, a.Value)
+		return true
+	}
+	for _, a := range h.attrs {
+		write(a)
+	}
+	r.Attrs(write)
+	h.logger.Printf("%s", buf.String())
+	return nil
+}
+
+func (h *printfHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &printfHandler{logger: h.logger, attrs: append(append([]slog.Attr{}, h.attrs...), attrs...)}
+}
+
+func (h *printfHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+// 需要隐藏的字段，日志中替换为 ***
+var secretKeys = map[string]bool{
+	"access_token":             true,
+	"secret":                   true,
+	"appsecret":                true,
+	"refresh_token":            true,
+	"component_access_token":   true,
+	"authorizer_access_token":  true,
+	"authorizer_refresh_token": true,
+	"session_key":              true,
+	"invalid":                  true, // 请求中控服务器刷新时带上的失效 access_token
+}
+
+// 手机号字段，日志中只保留前3位和后4位
+var phoneKeys = map[string]bool{
+	"phoneNumber":     true,
+	"purePhoneNumber": true,
+	"phone_number":    true,
+	"phone":           true,
+	"mobile":          true,
+}
+
+// MaskBody 返回隐藏了 access_token、secret、session_key、手机号等字段的副本，文件只保留文件名和大小，用于记录日志
+func MaskBody(body map[string]interface{}) map[string]interface{} {
+	if body == nil {
+		return nil
+	}
+	return maskValue("", body).(map[string]interface{})
+}
+
+// MaskJSON 隐藏 json 中的敏感字段，非 json 内容只返回长度
+func MaskJSON(bs []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(bs, &v); err != nil {
+		return fmt.Sprintf("<%d bytes>", len(bs))
+	}
+	masked, err := json.Marshal(maskValue("", v))
+	if err != nil {
+		return fmt.Sprintf("<%d bytes>", len(bs))
+	}
+	return string(masked)
+}
+
+func maskValue(key string, v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			masked[k] = maskValue(k, item)
+		}
+		return masked
+	case BodyMap:
+		return maskValue(key, map[string]interface{}(val))
+	case []interface{}:
+		masked := make([]interface{}, len(val))
+		for i, item := range val {
+			masked[i] = maskValue(key, item)
+		}
+		return masked
+	case *util.File:
+		size, _ := val.Len()
+		return fmt.Sprintf("<file %s %d bytes>", val.Name, size)
+	case string:
+		switch {
+		case secretKeys[key]:
+			return "***"
+		case phoneKeys[key]:
+			return maskPhone(val)
+		}
+	}
+	if secretKeys[key] {
+		return "***"
+	}
+	return v
+}
+
+// 13812345678 => 138****5678
+func maskPhone(phone string) string {
+	if len(phone) < 7 {
+		return "***"
+	}
+	return phone[:3] + strings.Repeat("*", len(phone)-7) + phone[len(phone)-4:]
+}
@@ -0,0 +1,69 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientSlogMasking(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errcode":0,"phone_info":{"phoneNumber":"13812345678","purePhoneNumber":"13812345678"},"session_key":"SESSION"}`))
+	}))
+	defer srv.Close()
+
+	for _, level := range []slog.Level{slog.LevelInfo, slog.LevelDebug} {
+		buf := &bytes.Buffer{}
+		logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: level}))
+		c := NewClient(StaticTokenSource("TOKEN"), WithBaseURL(srv.URL), WithSlog(logger))
+		if err := c.DoRequestPost(context.Background(), "/wxa/business/getuserphonenumber", BodyMap{"code": "CODE", "secret": "SECRET"}, &WxCommonResponse{}); err != nil {
+			t.Fatal(err)
+		}
+
+		out := buf.String()
+		for _, secret := range []string{"TOKEN", "SECRET", "SESSION", "13812345678"} {
+			if strings.Contains(out, secret) {
+				t.Fatalf("level %s: %s not masked: %s", level, secret, out)
+			}
+		}
+		if debug := strings.Contains(out, "138****5678"); debug != (level == slog.LevelDebug) {
+			t.Fatalf("level %s: response body logged = %v: %s", level, debug, out)
+		}
+	}
+}
+
+func TestClientSlogErrorMasking(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(buf, nil))
+
+	// 拦截器直接返回包含完整地址的错误
+	uri := "https://api.weixin.qq.com/cgi-bin/menu/get?access_token=SECRET_TOKEN"
+	next := func(ctx context.Context, req *Request) (*Response, error) {
+		return nil, fmt.Errorf("wrapped: %w", &url.Error{Op: "Get", URL: uri, Err: errors.New("connection refused")})
+	}
+	LoggingInterceptor(logger)(context.Background(), &Request{Method: http.MethodGet, URL: uri}, next)
+
+	// 网络错误重试、切换域名时的日志
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+	c := NewClient(StaticTokenSource("SECRET_TOKEN"), WithBaseURL(downURL), WithFailoverBaseURLs(downURL), WithSlog(logger),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}))
+	c.DoRequestGet(context.Background(), "/cgi-bin/get_api_domain_ip", &WxCommonResponse{})
+
+	out := buf.String()
+	if !strings.Contains(out, "connection refused") {
+		t.Fatalf("errors not logged: %s", out)
+	}
+	if strings.Contains(out, "SECRET_TOKEN") {
+		t.Fatalf("access_token not masked: %s", out)
+	}
+}
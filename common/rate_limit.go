@@ -0,0 +1,144 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited 等待限流的时间超过了 ctx 的截止时间
+var ErrRateLimited = errors.New("client rate limit exceeded")
+
+// RateLimit 令牌桶限流配置
+type RateLimit struct {
+	Rate  float64 // 每秒生成的令牌数，小于等于0时不限流
+	Burst int     // 令牌桶容量，即允许的突发请求数，小于1时为1
+}
+
+// PerSecond 每秒最多 n 次
+func PerSecond(n int) RateLimit {
+	return RateLimit{Rate: float64(n), Burst: n}
+}
+
+// PerMinute 每分钟最多 n 次，允许一次性用完
+func PerMinute(n int) RateLimit {
+	return RateLimit{Rate: float64(n) / 60, Burst: n}
+}
+
+// RateLimiter 按接口路径限流，超过频率时等待
+// 微信的调用频率限制按 AppID 计算，每个 AppID 使用一个 RateLimiter，多个实例部署时需要按实例数分摊频率
+type RateLimiter struct {
+	mu      sync.Mutex
+	limits  map[string]RateLimit // 接口路径 => 限流配置
+	buckets map[string]*tokenBucket
+}
+
+// NewRateLimiter 创建限流器，limits 的 key 为接口路径，如 /cgi-bin/message/template/send
+func NewRateLimiter(limits map[string]RateLimit) *RateLimiter {
+	l := &RateLimiter{
+		limits:  make(map[string]RateLimit, len(limits)),
+		buckets: make(map[string]*tokenBucket),
+	}
+	for path, limit := range limits {
+		l.limits[path] = limit
+	}
+	return l
+}
+
+// SetLimit 设置接口的限流配置，会重置该接口的令牌桶
+func (l *RateLimiter) SetLimit(path string, limit RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits[path] = limit
+	delete(l.buckets, path)
+}
+
+// Wait 等待 path 接口可以发起请求，ctx 取消或等待时间超过截止时间时返回错误
+func (l *RateLimiter) Wait(ctx context.Context, path string) error {
+	l.mu.Lock()
+	bucket := l.bucket(path)
+	if bucket == nil {
+		l.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	wait := bucket.reserve(now)
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(wait)) {
+		l.cancel(bucket)
+		return fmt.Errorf("%w: %s need wait %s", ErrRateLimited, path, wait)
+	}
+	if err := sleepContext(ctx, wait); err != nil {
+		l.cancel(bucket)
+		return err
+	}
+	return nil
+}
+
+// Interceptor 请求前按接口路径等待限流
+func (l *RateLimiter) Interceptor() Interceptor {
+	return func(ctx context.Context, req *Request, next RoundTripFunc) (*Response, error) {
+		u, err := url.Parse(req.URL)
+		if err != nil {
+			return nil, err
+		}
+		if err = l.Wait(ctx, u.Path); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+// 未配置限流的接口返回 nil
+func (l *RateLimiter) bucket(path string) *tokenBucket {
+	if bucket, ok := l.buckets[path]; ok {
+		return bucket
+	}
+	limit, ok := l.limits[path]
+	if !ok || limit.Rate <= 0 {
+		return nil
+	}
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	bucket := &tokenBucket{limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+	l.buckets[path] = bucket
+	return bucket
+}
+
+// 放弃等待时归还令牌
+func (l *RateLimiter) cancel(bucket *tokenBucket) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	bucket.tokens++
+}
+
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64 // 为负数时表示已被预订、需要等待的令牌
+	last   time.Time
+}
+
+// 预订一个令牌，返回需要等待的时间
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.limit.Rate
+		if burst := float64(b.limit.Burst); b.tokens > burst {
+			b.tokens = burst
+		}
+		b.last = now
+	}
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.limit.Rate * float64(time.Second))
+}
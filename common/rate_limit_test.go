@@ -0,0 +1,60 @@
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Now()
+	b := &tokenBucket{limit: RateLimit{Rate: 20, Burst: 2}, tokens: 2, last: start}
+
+	// 前两次使用桶中的令牌，之后每次多等待 50ms
+	for i, want := range []time.Duration{0, 0, 50 * time.Millisecond, 100 * time.Millisecond} {
+		if wait := b.reserve(start); wait != want {
+			t.Fatalf("reserve %d: wait = %s, want %s", i, wait, want)
+		}
+	}
+
+	// 200ms 后生成4个令牌，还清预订的2个后剩余2个
+	if wait := b.reserve(start.Add(200 * time.Millisecond)); wait != 0 {
+		t.Fatalf("wait after refill = %s", wait)
+	}
+	if b.tokens != 1 {
+		t.Fatalf("tokens = %v, want 1", b.tokens)
+	}
+
+	// 令牌数不超过桶容量
+	b.reserve(start.Add(time.Hour))
+	if b.tokens != 1 {
+		t.Fatalf("tokens = %v, want 1", b.tokens)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	const path = "/cgi-bin/message/template/send"
+	l := NewRateLimiter(map[string]RateLimit{
+		path: {Rate: 1, Burst: 1},
+	})
+	ctx := context.Background()
+
+	if err := l.Wait(ctx, path); err != nil {
+		t.Fatal(err)
+	}
+	// 未配置的接口不限流
+	if err := l.Wait(ctx, "/cgi-bin/menu/get"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 需要等待约1秒，超过 ctx 的截止时间
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(timeoutCtx, path); !IsRateLimited(err) {
+		t.Fatalf("Wait() = %v, want ErrRateLimited", err)
+	}
+	// 放弃等待时归还令牌，否则为 -1
+	if tokens := l.buckets[path].tokens; tokens < -0.5 {
+		t.Fatalf("tokens = %v, want about 0", tokens)
+	}
+}
@@ -4,10 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"time"
-
-	"github.com/medreams/wechat/pkg/util"
-	"github.com/medreams/wechat/pkg/xhttp"
+	"net/http"
 )
 
 type WxCommonResponse struct {
@@ -16,8 +13,28 @@ type WxCommonResponse struct {
 }
 
 func DoRequestGet(c context.Context, uri string, ptr interface{}) (err error) {
+	return doRequestGet(c, nil, uri, ptr)
+}
+
+func DoRequestGetByte(c context.Context, uri string) (bs []byte, err error) {
+	return doRequestGetByte(c, nil, uri)
+}
+
+func DoRequestPost(c context.Context, uri string, body map[string]interface{}, ptr interface{}) (err error) {
+	return doRequestPost(c, nil, uri, body, ptr)
+}
+
+func DoRequestPostByte(c context.Context, uri string, body map[string]interface{}) (bs []byte, err error) {
+	return doRequestPostByte(c, nil, uri, body)
+}
+
+func DoUploadFile(c context.Context, uri string, body map[string]interface{}, ptr interface{}) error {
+	return doUploadFile(c, nil, uri, body, ptr)
+}
+
+func doRequestGet(c context.Context, rt RoundTripFunc, uri string, ptr interface{}) (err error) {
 
-	bs, err := DoRequestGetByte(c, uri)
+	bs, err := doRequestGetByte(c, rt, uri)
 	if err != nil {
 		return err
 	}
@@ -29,29 +46,32 @@ func DoRequestGet(c context.Context, uri string, ptr interface{}) (err error) {
 	return
 }
 
-func DoRequestGetByte(c context.Context, uri string) (bs []byte, err error) {
-	httpClient := xhttp.NewClient()
+func doRequestGetByte(c context.Context, rt RoundTripFunc, uri string) (bs []byte, err error) {
+	return roundTrip(c, rt, newRequest(http.MethodGet, uri, nil))
+}
 
-	httpClient.Header.Add(xhttp.HeaderRequestID, fmt.Sprintf("%s-%d", util.RandomString(21), time.Now().Unix()))
-	res, bs, err := httpClient.Get(uri).EndBytes(c)
-	if err != nil {
-		return nil, fmt.Errorf("http.request(GET, %s)：%w", uri, err)
-	}
+func doRequestPost(c context.Context, rt RoundTripFunc, uri string, body map[string]interface{}, ptr interface{}) (err error) {
 
-	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("StatusCode(%d) != 200", res.StatusCode)
+	bs, err := doRequestPostByte(c, rt, uri, body)
+	if err != nil {
+		return err
 	}
 
-	if err := CheckRequestError(bs); err != nil {
-		return nil, err
+	if err = json.Unmarshal(bs, ptr); err != nil {
+		return fmt.Errorf("json.Unmarshal(%s, %+v)：%w", string(bs), ptr, err)
 	}
+	return
+}
 
-	return bs, nil
+func doRequestPostByte(c context.Context, rt RoundTripFunc, uri string, body map[string]interface{}) (bs []byte, err error) {
+	return roundTrip(c, rt, newRequest(http.MethodPost, uri, body))
 }
 
-func DoRequestPost(c context.Context, uri string, body map[string]interface{}, ptr interface{}) (err error) {
+func doUploadFile(c context.Context, rt RoundTripFunc, uri string, body map[string]interface{}, ptr interface{}) error {
+	req := newRequest(http.MethodPost, uri, body)
+	req.Multipart = true
 
-	bs, err := DoRequestPostByte(c, uri, body)
+	bs, err := roundTrip(c, rt, req)
 	if err != nil {
 		return err
 	}
@@ -59,62 +79,63 @@ func DoRequestPost(c context.Context, uri string, body map[string]interface{}, p
 	if err = json.Unmarshal(bs, ptr); err != nil {
 		return fmt.Errorf("json.Unmarshal(%s, %+v)：%w", string(bs), ptr, err)
 	}
-	return
+
+	return nil
 }
 
-func DoRequestPostByte(c context.Context, uri string, body map[string]interface{}) (bs []byte, err error) {
-	httpClient := xhttp.NewClient()
+// 经过拦截器发送请求并检查状态码和 errcode，rt 为 nil 时使用默认的 http.Client
+func roundTrip(c context.Context, rt RoundTripFunc, req *Request) ([]byte, error) {
+	if rt == nil {
+		rt = httpRoundTrip(nil)
+	}
+	uri := req.URL
 
-	httpClient.Header.Add(xhttp.HeaderRequestID, fmt.Sprintf("%s-%d", util.RandomString(21), time.Now().Unix()))
-	res, bs, err := httpClient.Post(uri).SendBodyMap(body).EndBytes(c)
+	res, err := rt(c, req)
 	if err != nil {
-		return nil, fmt.Errorf("http.request(POST, %s)：%w", uri, err)
+		return nil, fmt.Errorf("http.request(%s, %s)：%w", req.Method, RedactURL(uri), RedactError(err))
 	}
 
 	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("StatusCode(%d) != 200", res.StatusCode)
+		return nil, &statusError{code: res.StatusCode}
 	}
 
-	if err := CheckRequestError(bs); err != nil {
+	if err := checkResponse(uri, res.Body); err != nil {
 		return nil, err
 	}
 
-	return bs, nil
+	return res.Body, nil
 }
 
-func DoUploadFile(c context.Context, uri string, body map[string]interface{}, ptr interface{}) error {
-	httpClient := xhttp.NewClient()
-	httpClient.Header.Add(xhttp.HeaderRequestID, fmt.Sprintf("%s-%d", util.RandomString(21), time.Now().Unix()))
-	res, bs, err := httpClient.Type(xhttp.TypeMultipartFormData).
-		Post(uri).
-		SendMultipartBodyMap(body).
-		EndBytes(c)
-	if err != nil {
-		return fmt.Errorf("http.request(POST, %s)：%w", uri, err)
-	}
+// HTTP 状态码不是200
+type statusError struct {
+	code int
+}
 
-	if res.StatusCode != 200 {
-		return fmt.Errorf("StatusCode(%d) != 200", res.StatusCode)
-	}
+func (e *statusError) Error() string {
+	return fmt.Sprintf("StatusCode(%d) != 200", e.code)
+}
 
-	if err := CheckRequestError(bs); err != nil {
-		return err
-	}
+// CheckRequestError 检查接口返回的 errcode，不为0时返回 *APIError
+func CheckRequestError(bs []byte) error {
 
-	if err = json.Unmarshal(bs, ptr); err != nil {
-		return fmt.Errorf("json.Unmarshal(%s, %+v)：%w", string(bs), ptr, err)
+	msg := &WxCommonResponse{}
+	json.Unmarshal(bs, msg)
+	if msg.ErrCode != 0 {
+		return NewAPIError(msg.ErrCode, msg.ErrMsg)
 	}
 
 	return nil
 }
 
-func CheckRequestError(bs []byte) error {
-
+// 检查接口返回的 errcode，按接口所属产品线生成错误码说明，错误中带上请求地址
+func checkResponse(uri string, bs []byte) error {
 	msg := &WxCommonResponse{}
 	json.Unmarshal(bs, msg)
-	if msg.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d),ErrMsg(%s)", msg.ErrCode, msg.ErrMsg)
+	if msg.ErrCode == 0 {
+		return nil
 	}
 
-	return nil
+	apiErr := newAPIError(productOf(uri), msg.ErrCode, msg.ErrMsg)
+	apiErr.URL = RedactURL(uri)
+	return apiErr
 }
@@ -0,0 +1,139 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/medreams/wechat/pkg/util"
+)
+
+// RetryPolicy 请求失败重试策略
+// 可重试的 errcode、网络错误、5xx 只重试 GET 请求和带 client_msg_id 的 POST 请求，避免重复提交
+type RetryPolicy struct {
+	MaxAttempts        int           // 最多请求次数（包含第一次），小于等于1时不重试
+	Backoff            time.Duration // 第一次重试前的等待时间
+	MaxBackoff         time.Duration // 等待时间上限，为0时不限制
+	Multiplier         float64       // 每次重试等待时间的增长倍数，小于等于0时为2
+	Jitter             float64       // 等待时间随机浮动的比例，0~1，例如0.2表示在 ±20% 内浮动
+	RetryableErrCodes  []int64       // 可重试的 errcode，为 nil 时为 -1（系统繁忙）
+	RetryNonIdempotent bool          // 也重试不带 client_msg_id 的 POST 请求，可能重复提交
+}
+
+// DefaultRetryPolicy 最多请求3次，等待 200ms、400ms，浮动 ±20%
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     200 * time.Millisecond,
+		MaxBackoff:  2 * time.Second,
+		Multiplier:  2,
+		Jitter:      0.2,
+	}
+}
+
+// 45011 等按分钟计算的频率限制，短时间内重试没有意义，不默认重试
+var defaultRetryableErrCodes = []int64{-1}
+
+// 请求失败后是否重试，idempotent 表示重复请求不会重复提交
+func (p RetryPolicy) shouldRetry(ctx context.Context, idempotent bool, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	// errcode 为 -1 时请求可能已经处理，同样不能重复提交
+	if !idempotent && !p.RetryNonIdempotent {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		codes := p.RetryableErrCodes
+		if codes == nil {
+			codes = defaultRetryableErrCodes
+		}
+		for _, code := range codes {
+			if apiErr.Code == code {
+				return true
+			}
+		}
+		return false
+	}
+
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code >= http.StatusInternalServerError
+	}
+	return isNetworkError(ctx, err)
+}
+
+// 第 attempt 次请求失败后的等待时间
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	d := float64(p.Backoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// 请求体带有 client_msg_id 时微信会去重，可以安全重试
+func isIdempotent(body map[string]interface{}) bool {
+	id, ok := body["client_msg_id"].(string)
+	return ok && id != ""
+}
+
+// 请求体中的文件是否都可以重新读取
+func rewindable(body map[string]interface{}) bool {
+	for _, v := range body {
+		if file, ok := v.(*util.File); ok && !file.Rewindable() {
+			return false
+		}
+	}
+	return true
+}
+
+// 是否为网络层错误（连接失败、超时等），调用方主动取消的除外
+func isNetworkError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var ue *url.Error
+	return errors.As(err, &ue)
+}
+
+// 网络错误时是否可以切换域名重新请求，POST 请求只在连接未建立时切换，避免重复提交
+func canFailover(ctx context.Context, method string, err error) bool {
+	if !isNetworkError(ctx, err) {
+		return false
+	}
+	if method == http.MethodGet {
+		return true
+	}
+	var oe *net.OpError
+	return errors.As(err, &oe) && oe.Op == "dial"
+}
+
+// 等待 d，ctx 取消时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,71 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// Stream 接口返回的文件流，读取后需要 Close
+type Stream struct {
+	io.ReadCloser
+	ContentType   string // 如 image/jpeg，获取图文、视频素材等返回 json 时为 application/json
+	ContentLength int64  // 未知时为 -1
+	Filename      string // Content-Disposition 中的文件名，没有时为空
+
+	uri string
+}
+
+func newStream(res *Response) *Stream {
+	stream := &Stream{
+		ReadCloser:    res.Stream,
+		ContentType:   res.Header.Get("Content-Type"),
+		ContentLength: -1,
+	}
+	if stream.ReadCloser == nil {
+		stream.ReadCloser = io.NopCloser(bytes.NewReader(res.Body))
+		stream.ContentLength = int64(len(res.Body))
+	} else if res.ContentLength > 0 {
+		stream.ContentLength = res.ContentLength
+	} else if n, err := strconv.ParseInt(res.Header.Get("Content-Length"), 10, 64); err == nil {
+		// 拦截器自行构造的响应
+		stream.ContentLength = n
+	}
+	if _, params, err := mime.ParseMediaType(res.Header.Get("Content-Disposition")); err == nil {
+		stream.Filename = params["filename"]
+	}
+	return stream
+}
+
+// 以流的方式请求，返回 json 时检查 errcode
+func doRequestStream(c context.Context, rt RoundTripFunc, req *Request) (*Stream, error) {
+	if rt == nil {
+		rt = httpRoundTrip(nil)
+	}
+	req.Stream = true
+	uri := req.URL
+
+	res, err := rt(c, req)
+	if err != nil {
+		return nil, fmt.Errorf("http.request(%s, %s)：%w", req.Method, RedactURL(uri), RedactError(err))
+	}
+	if res.StatusCode != http.StatusOK {
+		if res.Stream != nil {
+			res.Stream.Close()
+		}
+		return nil, &statusError{code: res.StatusCode}
+	}
+	if res.Stream == nil {
+		if err := checkResponse(uri, res.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	stream := newStream(res)
+	stream.uri = uri
+	return stream, nil
+}
@@ -0,0 +1,143 @@
+package common
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// 获取新的 access_token，invalid 不为空时为强制刷新，invalid 为已失效的 access_token
+type tokenRenewFunc func(ctx context.Context, invalid string) (*WxAccessToken, error)
+
+// tokenCache 缓存 access_token，TokenManager、HTTPTokenSource 共用
+// 过期前 renewBefore 刷新，并发刷新合并为一次；刷新失败后 renewRetryInterval 内继续使用仍有效的旧 access_token
+type tokenCache struct {
+	mu        sync.Mutex
+	current   *WxAccessToken
+	nextRenew time.Time
+	call      *tokenCall
+}
+
+// 正在进行中的刷新
+type tokenCall struct {
+	done    chan struct{}
+	invalid string // 不为空时为强制刷新，invalid 为已失效的 access_token
+	at      *WxAccessToken
+	err     error
+}
+
+// 获取可用的 access_token，临近过期时刷新
+func (c *tokenCache) get(ctx context.Context, renewBefore time.Duration, renew tokenRenewFunc) (*WxAccessToken, error) {
+	c.mu.Lock()
+	current := c.current
+	if current != nil && !c.needRenew(current, renewBefore) {
+		c.mu.Unlock()
+		return current, nil
+	}
+	call := c.startCall(ctx, "", renewBefore, renew)
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if call.err != nil {
+		// 刷新失败但旧的 access_token 仍在有效期内，继续使用
+		if current != nil && !expired(current) {
+			return current, nil
+		}
+		return nil, call.err
+	}
+	return call.at, nil
+}
+
+// 强制刷新，已经换成新的 access_token 时直接使用，不会重复强制刷新
+func (c *tokenCache) refresh(ctx context.Context, invalid string, renewBefore time.Duration, renew tokenRenewFunc) (string, error) {
+	for {
+		c.mu.Lock()
+		if current := c.current; current != nil && current.AccessToken != invalid && !expired(current) {
+			c.mu.Unlock()
+			return current.AccessToken, nil
+		}
+		call := c.call
+		if call == nil {
+			call = c.startCall(ctx, invalid, renewBefore, renew)
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+
+		if call.err != nil {
+			return "", call.err
+		}
+		// 等到的是普通刷新且仍是失效的 access_token 时，再发起强制刷新
+		if call.invalid == "" && call.at.AccessToken == invalid {
+			continue
+		}
+		return call.at.AccessToken, nil
+	}
+}
+
+// 替换缓存的 access_token，at 为 nil 时清除
+func (c *tokenCache) set(at *WxAccessToken) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current = at
+	c.nextRenew = time.Time{}
+}
+
+// 是否需要刷新，需持有 c.mu
+func (c *tokenCache) needRenew(at *WxAccessToken, renewBefore time.Duration) bool {
+	// 外部设置且没有过期时间的 access_token 不刷新
+	if at.ExpiresTime == 0 {
+		return false
+	}
+	now := time.Now()
+	if now.Before(c.nextRenew) && !expired(at) {
+		return false
+	}
+	return now.Add(renewBefore).Unix() >= at.ExpiresTime
+}
+
+// 发起刷新，已有刷新进行中时复用，需持有 c.mu
+func (c *tokenCache) startCall(ctx context.Context, invalid string, renewBefore time.Duration, renew tokenRenewFunc) *tokenCall {
+	if c.call != nil {
+		return c.call
+	}
+
+	call := &tokenCall{done: make(chan struct{}), invalid: invalid}
+	c.call = call
+
+	// 刷新不受单个调用方取消的影响，避免一个调用方超时导致其他等待者全部失败
+	go func() {
+		at, err := renew(context.WithoutCancel(ctx), invalid)
+
+		c.mu.Lock()
+		if err == nil {
+			c.current = at
+			c.nextRenew = time.Unix(at.ExpiresTime, 0).Add(-renewBefore)
+			if min := time.Now().Add(renewRetryInterval); c.nextRenew.Before(min) {
+				c.nextRenew = min
+			}
+		} else {
+			c.nextRenew = time.Now().Add(renewRetryInterval)
+		}
+		c.call = nil
+		c.mu.Unlock()
+
+		call.at, call.err = at, err
+		close(call.done)
+	}()
+
+	return call
+}
+
+func expired(at *WxAccessToken) bool {
+	return at.ExpiresTime != 0 && time.Now().Unix() >= at.ExpiresTime
+}
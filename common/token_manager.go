@@ -0,0 +1,188 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/medreams/wechat/pkg/xhttp"
+)
+
+const (
+	// DefaultRenewBefore access_token 过期前多久开始刷新，stable_token 在过期前5分钟内会返回新的 access_token
+	DefaultRenewBefore = 5 * time.Minute
+	// 刷新失败后，旧的 access_token 仍有效时的重试间隔
+	renewRetryInterval = 30 * time.Second
+	// 多实例互斥刷新时锁的有效期比刷新的超时时间多出的部分，刷新超时后锁才会被其他实例视为过期
+	tokenLockMargin = 30 * time.Second
+)
+
+// TokenManager 管理 access_token 的获取与刷新
+// 过期前 renewBefore 主动刷新，并发刷新合并为一次 stable_token 请求，刷新结果写入 TokenStore 供其他实例共用
+type TokenManager struct {
+	appid       string
+	secret      string
+	store       TokenStore
+	renewBefore time.Duration
+	client      *Client // 请求 stable_token 使用，为 nil 时使用默认的 http.Client
+	fetch       func(ctx context.Context, appid, secret string, forceRefresh bool) (*WxAccessToken, error)
+
+	mu    sync.Mutex // 保护 client、renewBefore
+	cache tokenCache
+}
+
+// NewTokenManager 创建 access_token 管理器，store 为 nil 时使用进程内存储
+func NewTokenManager(appid, secret string, store TokenStore) *TokenManager {
+	if store == nil {
+		store = NewMemoryTokenStore()
+	}
+	m := &TokenManager{
+		appid:       appid,
+		secret:      secret,
+		store:       store,
+		renewBefore: DefaultRenewBefore,
+	}
+	m.fetch = func(ctx context.Context, appid, secret string, forceRefresh bool) (*WxAccessToken, error) {
+		return getStableToken(ctx, m.client, appid, secret, forceRefresh)
+	}
+	return m
+}
+
+// SetClient 设置请求 stable_token 使用的客户端，共用其 http.Client、域名、超时等配置
+func (m *TokenManager) SetClient(c *Client) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c != nil {
+		c = c.WithoutToken()
+	}
+	m.client = c
+}
+
+// SetRenewBefore 设置过期前多久开始刷新
+func (m *TokenManager) SetRenewBefore(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.renewBefore = d
+}
+
+// Token 获取可用的 access_token，实现 TokenSource
+func (m *TokenManager) Token(ctx context.Context) (string, error) {
+	at, err := m.AccessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	return at.AccessToken, nil
+}
+
+// AccessToken 获取可用的 access_token 及其过期时间，临近过期时自动刷新
+func (m *TokenManager) AccessToken(ctx context.Context) (*WxAccessToken, error) {
+	return m.cache.get(ctx, m.getRenewBefore(), m.renew)
+}
+
+// RefreshToken 接口返回 access_token 失效时强制刷新，实现 TokenRefresher
+// 本实例或其他实例已经换成新的 access_token 时直接使用，不会重复强制刷新
+func (m *TokenManager) RefreshToken(ctx context.Context, invalid string) (string, error) {
+	return m.cache.refresh(ctx, invalid, m.getRenewBefore(), m.renew)
+}
+
+// SetAccessToken 设置外部获取的 access_token，同时写入存储
+func (m *TokenManager) SetAccessToken(ctx context.Context, at WxAccessToken) error {
+	if at.AccessToken == "" {
+		return errors.New("access_token is empty")
+	}
+	// 只设置了 ExpiresIn 时与获取 stable_token 一样计算过期时间
+	if at.ExpiresTime == 0 && at.ExpiresIn > 0 {
+		at.ExpiresTime = time.Now().Unix() + int64(at.ExpiresIn)
+	}
+
+	m.cache.set(&at)
+
+	if at.ExpiresTime == 0 {
+		return nil
+	}
+	return SaveAccessToken(ctx, m.store, m.appid, &at)
+}
+
+// Clean 清除本地和存储中的 access_token
+func (m *TokenManager) Clean(ctx context.Context) error {
+	m.cache.set(nil)
+
+	return m.store.Delete(ctx, AccessTokenKey(m.appid))
+}
+
+func (m *TokenManager) getRenewBefore() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.renewBefore
+}
+
+// 优先使用存储中其他实例刷新过的 access_token，否则请求新的 access_token
+// invalid 不为空时强制刷新，存储中与 invalid 相同的 access_token 视为失效
+func (m *TokenManager) renew(ctx context.Context, invalid string) (*WxAccessToken, error) {
+	at, err := m.loadFresh(ctx, invalid)
+	if err != nil || at != nil {
+		return at, err
+	}
+
+	timeout := m.fetchTimeout()
+	if locker, ok := m.store.(TokenLocker); ok {
+		unlock, err := locker.Lock(ctx, AccessTokenKey(m.appid), timeout+tokenLockMargin)
+		if err != nil {
+			return nil, fmt.Errorf("lock access_token: %w", err)
+		}
+		defer unlock()
+
+		// 等待锁的过程中其他实例可能已经刷新
+		if at, err = m.loadFresh(ctx, invalid); err != nil || at != nil {
+			return at, err
+		}
+	}
+
+	if m.secret == "" {
+		return nil, errors.New("appsecret is empty, cannot refresh access_token")
+	}
+
+	// 刷新（包含重试）不超过锁的有效期，避免锁过期后其他实例重复强制刷新
+	fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	at, err = m.fetch(fetchCtx, m.appid, m.secret, invalid != "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = SaveAccessToken(ctx, m.store, m.appid, at); err != nil {
+		return nil, err
+	}
+	return at, nil
+}
+
+// 请求 stable_token 的超时时间，取 client 设置的超时时间，都未设置时与 xhttp.DefaultHttpClient 相同
+func (m *TokenManager) fetchTimeout() time.Duration {
+	m.mu.Lock()
+	c := m.client
+	m.mu.Unlock()
+
+	switch {
+	case c == nil:
+	case c.timeout > 0:
+		return c.timeout
+	case c.httpClient != nil && c.httpClient.Timeout > 0:
+		return c.httpClient.Timeout
+	}
+	return xhttp.DefaultHttpClient.Timeout
+}
+
+// 读取存储中不需要刷新的 access_token
+func (m *TokenManager) loadFresh(ctx context.Context, invalid string) (*WxAccessToken, error) {
+	at, err := LoadAccessToken(ctx, m.store, m.appid)
+	if err != nil || at == nil || (invalid != "" && at.AccessToken == invalid) {
+		return nil, err
+	}
+
+	if at.ExpiresTime != 0 && time.Now().Add(m.getRenewBefore()).Unix() >= at.ExpiresTime {
+		return nil, nil
+	}
+	return at, nil
+}
@@ -0,0 +1,178 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenManagerSingleFlight(t *testing.T) {
+	var calls int32
+	m := NewTokenManager("wx_single_flight", "secret", NewMemoryTokenStore())
+	m.fetch = func(ctx context.Context, appid, secret string, forceRefresh bool) (*WxAccessToken, error) {
+		n := atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		return &WxAccessToken{
+			AccessToken: fmt.Sprintf("token-%d", n),
+			ExpiresIn:   7200,
+			ExpiresTime: time.Now().Unix() + 7200,
+		}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := m.Token(context.Background())
+			if err != nil || token != "token-1" {
+				t.Errorf("Token() = %s, %v", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("fetch called %d times, want 1", calls)
+	}
+}
+
+func TestTokenManagerRenewBeforeExpire(t *testing.T) {
+	var calls int32
+	m := NewTokenManager("wx_renew", "secret", NewMemoryTokenStore())
+	m.fetch = func(ctx context.Context, appid, secret string, forceRefresh bool) (*WxAccessToken, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return &WxAccessToken{
+			AccessToken: fmt.Sprintf("token-%d", n),
+			ExpiresIn:   7200,
+			ExpiresTime: time.Now().Unix() + 7200,
+		}, nil
+	}
+
+	// 剩余时间小于 renewBefore 时主动刷新
+	m.SetAccessToken(context.Background(), WxAccessToken{
+		AccessToken: "old",
+		ExpiresTime: time.Now().Unix() + 60,
+	})
+
+	token, err := m.Token(context.Background())
+	if err != nil || token != "token-1" {
+		t.Fatalf("Token() = %s, %v, want token-1", token, err)
+	}
+
+	// 刷新失败时继续使用仍在有效期内的旧 access_token
+	var failed int32
+	m.fetch = func(ctx context.Context, appid, secret string, forceRefresh bool) (*WxAccessToken, error) {
+		atomic.AddInt32(&failed, 1)
+		return nil, errors.New("system busy")
+	}
+	m.SetRenewBefore(3 * time.Hour)
+	m.cache.nextRenew = time.Time{}
+	token, err = m.Token(context.Background())
+	if err != nil || token != "token-1" {
+		t.Fatalf("Token() = %s, %v, want token-1", token, err)
+	}
+	// renewRetryInterval 内不再刷新
+	if token, _ = m.Token(context.Background()); token != "token-1" || failed != 1 {
+		t.Fatalf("Token() = %s, fetch called %d times after failure", token, failed)
+	}
+}
+
+func TestTokenManagerSetAccessTokenExpiresIn(t *testing.T) {
+	var calls int32
+	store := NewMemoryTokenStore()
+	m := NewTokenManager("wx_expires_in", "secret", store)
+	m.fetch = func(ctx context.Context, appid, secret string, forceRefresh bool) (*WxAccessToken, error) {
+		atomic.AddInt32(&calls, 1)
+		return &WxAccessToken{AccessToken: "fresh", ExpiresTime: time.Now().Unix() + 7200}, nil
+	}
+
+	// 只设置 ExpiresIn 时计算过期时间，临近过期同样主动刷新
+	if err := m.SetAccessToken(context.Background(), WxAccessToken{AccessToken: "external", ExpiresIn: 60}); err != nil {
+		t.Fatal(err)
+	}
+	at, err := LoadAccessToken(context.Background(), store, "wx_expires_in")
+	if err != nil || at == nil || at.ExpiresTime == 0 {
+		t.Fatalf("stored access_token = %+v, %v", at, err)
+	}
+	if token, err := m.Token(context.Background()); err != nil || token != "fresh" || calls != 1 {
+		t.Fatalf("Token() = %s, %v, calls = %d", token, err, calls)
+	}
+}
+
+func TestTokenManagerRefreshToken(t *testing.T) {
+	var forced int32
+	m := NewTokenManager("wx_refresh", "secret", NewMemoryTokenStore())
+	m.fetch = func(ctx context.Context, appid, secret string, forceRefresh bool) (*WxAccessToken, error) {
+		if !forceRefresh {
+			return nil, errors.New("want force refresh")
+		}
+		atomic.AddInt32(&forced, 1)
+		time.Sleep(20 * time.Millisecond)
+		return &WxAccessToken{
+			AccessToken: "fresh",
+			ExpiresIn:   7200,
+			ExpiresTime: time.Now().Unix() + 7200,
+		}, nil
+	}
+	m.SetAccessToken(context.Background(), WxAccessToken{
+		AccessToken: "revoked",
+		ExpiresTime: time.Now().Unix() + 7200,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := m.RefreshToken(context.Background(), "revoked")
+			if err != nil || token != "fresh" {
+				t.Errorf("RefreshToken() = %s, %v", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if forced != 1 {
+		t.Fatalf("force refresh called %d times, want 1", forced)
+	}
+}
+
+type recordingLockStore struct {
+	*MemoryTokenStore
+	ttl time.Duration
+}
+
+func (s *recordingLockStore) Lock(ctx context.Context, key string, ttl time.Duration) (func(), error) {
+	s.ttl = ttl
+	return func() {}, nil
+}
+
+func TestTokenManagerLockTTL(t *testing.T) {
+	store := &recordingLockStore{MemoryTokenStore: NewMemoryTokenStore()}
+	m := NewTokenManager("wx_lock_ttl", "secret", store)
+	m.SetClient(NewClient(nil, WithHTTPClient(&http.Client{Timeout: 2 * time.Minute})))
+
+	var deadline time.Time
+	m.fetch = func(ctx context.Context, appid, secret string, forceRefresh bool) (*WxAccessToken, error) {
+		deadline, _ = ctx.Deadline()
+		return &WxAccessToken{AccessToken: "token", ExpiresTime: time.Now().Unix() + 7200}, nil
+	}
+	start := time.Now()
+	if _, err := m.Token(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 锁的有效期比刷新的超时时间长，持有锁的实例刷新时不会被其他实例抢占
+	if store.ttl <= 2*time.Minute {
+		t.Fatalf("lock ttl = %s, want > client timeout", store.ttl)
+	}
+	if deadline.IsZero() || deadline.Sub(start) >= store.ttl {
+		t.Fatalf("fetch deadline = %s after start, lock ttl = %s", deadline.Sub(start), store.ttl)
+	}
+}
@@ -0,0 +1,179 @@
+package common
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 中控服务器鉴权请求头
+const (
+	HeaderTokenTimestamp = "X-Token-Timestamp"
+	HeaderTokenNonce     = "X-Token-Nonce"
+	HeaderTokenSignature = "X-Token-Signature"
+)
+
+// AccessTokenProvider 可以提供 access_token 及其过期时间，TokenManager、HTTPTokenSource 均已实现
+type AccessTokenProvider interface {
+	AccessToken(ctx context.Context) (*WxAccessToken, error)
+}
+
+// SignTokenRequest 计算中控服务器请求签名 hex(HMAC-SHA256(secret, appid\ntimestamp\nnonce\ninvalid))
+func SignTokenRequest(secret, appid, timestamp, nonce, invalid string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(appid + "\n" + timestamp + "\n" + nonce + "\n" + invalid))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// 为中控服务器请求加上签名请求头，nonce 每次请求都不同，中控服务器会拒绝重复的 nonce
+func signTokenRequest(req *http.Request, secret, appid, invalid string) error {
+	nonce, err := newNonce()
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(HeaderTokenTimestamp, timestamp)
+	req.Header.Set(HeaderTokenNonce, nonce)
+	req.Header.Set(HeaderTokenSignature, SignTokenRequest(secret, appid, timestamp, nonce, invalid))
+	return nil
+}
+
+// 随机的 32 位十六进制字符串
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("rand.Read：%w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// TokenRelayHandler 中控服务器，将本进程管理的 access_token 提供给内部其他服务，配合 HTTPTokenSource 使用
+// 请求 GET ?appid=AppID[&invalid=失效的access_token]，需带上 X-Token-Timestamp、X-Token-Nonce、X-Token-Signature 请求头
+// 时间戳有效期内记录已使用的 nonce，拒绝重放的请求；多个实例之间不共享 nonce 记录
+type TokenRelayHandler struct {
+	secret  string
+	lookup  func(appid string) (AccessTokenProvider, bool)
+	maxSkew time.Duration
+
+	mu     sync.Mutex
+	nonces map[string]time.Time // nonce => 过期时间
+}
+
+// NewTokenRelayHandler 创建中控服务器，secret 为与调用方共享的签名密钥
+// lookup 根据 appid 查找 access_token 来源，账号不存在时返回 false，账号存在但无法提供过期时间时返回 nil, true
+func NewTokenRelayHandler(secret string, lookup func(appid string) (AccessTokenProvider, bool)) *TokenRelayHandler {
+	return &TokenRelayHandler{
+		secret:  secret,
+		lookup:  lookup,
+		maxSkew: 5 * time.Minute,
+		nonces:  make(map[string]time.Time),
+	}
+}
+
+func (h *TokenRelayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeRelayError(w, http.StatusMethodNotAllowed, 43001, "需要 GET 请求")
+		return
+	}
+
+	query := r.URL.Query()
+	appid, invalid := query.Get("appid"), query.Get("invalid")
+	if !h.verify(r, appid, invalid) {
+		writeRelayError(w, http.StatusUnauthorized, 48001, "invalid signature")
+		return
+	}
+
+	provider, ok := h.lookup(appid)
+	if !ok {
+		writeRelayError(w, http.StatusNotFound, 40013, "不合法的 appid")
+		return
+	}
+	if provider == nil {
+		writeRelayError(w, http.StatusNotImplemented, -1, "access_token source of this appid cannot be relayed")
+		return
+	}
+
+	ctx := r.Context()
+	if invalid != "" {
+		if refresher, ok := provider.(TokenRefresher); ok {
+			if _, err := refresher.RefreshToken(ctx, invalid); err != nil {
+				writeRelayError(w, http.StatusBadGateway, -1, err.Error())
+				return
+			}
+		}
+	}
+
+	at, err := provider.AccessToken(ctx)
+	if err != nil {
+		writeRelayError(w, http.StatusBadGateway, -1, err.Error())
+		return
+	}
+
+	rsp := &WxAccessToken{
+		AccessToken: at.AccessToken,
+		ExpiresTime: at.ExpiresTime,
+	}
+	if at.ExpiresTime > 0 {
+		rsp.ExpiresIn = int(at.ExpiresTime - time.Now().Unix())
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(rsp)
+}
+
+// 校验签名、时间戳和 nonce
+func (h *TokenRelayHandler) verify(r *http.Request, appid, invalid string) bool {
+	if h.secret == "" {
+		return false
+	}
+
+	timestamp, nonce := r.Header.Get(HeaderTokenTimestamp), r.Header.Get(HeaderTokenNonce)
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || nonce == "" {
+		return false
+	}
+	issued := time.Unix(ts, 0)
+	if skew := time.Since(issued); skew > h.maxSkew || skew < -h.maxSkew {
+		return false
+	}
+
+	expected := SignTokenRequest(h.secret, appid, timestamp, nonce, invalid)
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get(HeaderTokenSignature))) {
+		return false
+	}
+	// 时间戳过期后请求会被拒绝，nonce 只需记录到那时
+	return h.useNonce(nonce, issued.Add(h.maxSkew))
+}
+
+// 记录 nonce，已使用过时返回 false
+func (h *TokenRelayHandler) useNonce(nonce string, expiresAt time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	for n, t := range h.nonces {
+		if now.After(t) {
+			delete(h.nonces, n)
+		}
+	}
+	if _, used := h.nonces[nonce]; used {
+		return false
+	}
+	h.nonces[nonce] = expiresAt
+	return true
+}
+
+func writeRelayError(w http.ResponseWriter, status int, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&WxAccessToken{ErrCode: code, ErrMsg: msg})
+}
@@ -0,0 +1,101 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type relayProvider struct {
+	token   string
+	refresh []string
+}
+
+func (p *relayProvider) AccessToken(ctx context.Context) (*WxAccessToken, error) {
+	return &WxAccessToken{AccessToken: p.token, ExpiresTime: time.Now().Unix() + 7200}, nil
+}
+
+func (p *relayProvider) RefreshToken(ctx context.Context, invalid string) (string, error) {
+	p.refresh = append(p.refresh, invalid)
+	p.token = "token-2"
+	return p.token, nil
+}
+
+func TestTokenRelayHandler(t *testing.T) {
+	provider := &relayProvider{token: "token-1"}
+	h := NewTokenRelayHandler("relay-secret", func(appid string) (AccessTokenProvider, bool) {
+		switch appid {
+		case "wx_relay":
+			return provider, true
+		case "wx_static":
+			return nil, true
+		}
+		return nil, false
+	})
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	// 通过 HTTPTokenSource 获取和刷新
+	ts := &HTTPTokenSource{URL: srv.URL, AppId: "wx_relay", Secret: "relay-secret"}
+	if token, err := ts.Token(context.Background()); err != nil || token != "token-1" {
+		t.Fatalf("Token() = %s, %v", token, err)
+	}
+	if token, err := ts.RefreshToken(context.Background(), "token-1"); err != nil || token != "token-2" {
+		t.Fatalf("RefreshToken() = %s, %v", token, err)
+	}
+	if len(provider.refresh) != 1 || provider.refresh[0] != "token-1" {
+		t.Fatalf("refresh = %v", provider.refresh)
+	}
+
+	serve := func(appid, timestamp, nonce, signature string) int {
+		r := httptest.NewRequest(http.MethodGet, "/?appid="+appid, nil)
+		r.Header.Set(HeaderTokenTimestamp, timestamp)
+		r.Header.Set(HeaderTokenNonce, nonce)
+		r.Header.Set(HeaderTokenSignature, signature)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	sign := func(appid, timestamp, nonce string) string {
+		return SignTokenRequest("relay-secret", appid, timestamp, nonce, "")
+	}
+
+	if code := serve("wx_relay", now, "nonce-1", sign("wx_relay", now, "nonce-1")); code != http.StatusOK {
+		t.Fatalf("signed request: %d", code)
+	}
+	// 重放同一个请求
+	if code := serve("wx_relay", now, "nonce-1", sign("wx_relay", now, "nonce-1")); code != http.StatusUnauthorized {
+		t.Fatalf("replayed request: %d", code)
+	}
+	// 签名错误、篡改 appid 不会占用 nonce
+	if code := serve("wx_relay", now, "nonce-2", sign("wx_relay", now, "other")); code != http.StatusUnauthorized {
+		t.Fatalf("bad signature: %d", code)
+	}
+	if code := serve("wx_static", now, "nonce-2", sign("wx_relay", now, "nonce-2")); code != http.StatusUnauthorized {
+		t.Fatalf("tampered appid: %d", code)
+	}
+	if code := serve("wx_relay", now, "nonce-2", sign("wx_relay", now, "nonce-2")); code != http.StatusOK {
+		t.Fatalf("nonce consumed by rejected request: %d", code)
+	}
+	// 时间戳超出范围
+	for _, d := range []time.Duration{-6 * time.Minute, 6 * time.Minute} {
+		ts := strconv.FormatInt(time.Now().Add(d).Unix(), 10)
+		if code := serve("wx_relay", ts, "nonce-skew", sign("wx_relay", ts, "nonce-skew")); code != http.StatusUnauthorized {
+			t.Fatalf("timestamp skew %s: %d", d, code)
+		}
+	}
+	if code := serve("wx_relay", now, "", sign("wx_relay", now, "")); code != http.StatusUnauthorized {
+		t.Fatalf("empty nonce: %d", code)
+	}
+
+	if code := serve("wx_unknown", now, "nonce-3", sign("wx_unknown", now, "nonce-3")); code != http.StatusNotFound {
+		t.Fatalf("unknown appid: %d", code)
+	}
+	if code := serve("wx_static", now, "nonce-4", sign("wx_static", now, "nonce-4")); code != http.StatusNotImplemented {
+		t.Fatalf("appid without provider: %d", code)
+	}
+}
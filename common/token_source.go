@@ -0,0 +1,163 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/medreams/wechat/pkg/xhttp"
+)
+
+// TokenSource 提供调用接口所需的 access_token，每次请求都会调用
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenRefresher 可选实现，接口返回 access_token 失效时强制刷新，invalid 为失效的 access_token
+type TokenRefresher interface {
+	RefreshToken(ctx context.Context, invalid string) (string, error)
+}
+
+// StaticTokenSource 固定的 access_token，不会自动刷新
+type StaticTokenSource string
+
+func (s StaticTokenSource) Token(ctx context.Context) (string, error) {
+	if s == "" {
+		return "", errors.New("access_token is empty")
+	}
+	return string(s), nil
+}
+
+// TokenSourceFunc 使用函数获取 access_token，例如从配置中心、内部 RPC 获取
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// HTTPTokenSource 从中控服务器获取 access_token，不需要持有 AppSecret
+// 请求 GET URL?appid=AppId，返回 WxAccessToken 格式的 json；access_token 失效时带上 invalid 参数请求中控服务器刷新
+// 设置 Secret 时按 SignTokenRequest 签名，可直接对接 TokenRelayHandler
+// 获取到的 access_token 缓存到过期前 RenewBefore，并发获取合并为一次请求；获取失败后30秒内继续使用仍有效的缓存，不再请求
+type HTTPTokenSource struct {
+	URL         string        // 中控服务器地址
+	AppId       string        // AppID，中控服务器管理多个账号时用于区分
+	Secret      string        // 与中控服务器共享的签名密钥，为空时不签名
+	Header      http.Header   // 额外的请求头，可用于其他方式的鉴权
+	HTTPClient  *http.Client  // 为 nil 时使用默认的 http.Client
+	RenewBefore time.Duration // 过期前多久重新获取，默认1分钟
+
+	cache tokenCache
+}
+
+// WithHTTPClient 未设置 HTTPClient 时返回使用 hc 的副本，不修改 s，已设置时返回 s
+// WeChatSDK 使用它让请求中控服务器也使用 WithHTTPClient 设置的 http.Client
+func (s *HTTPTokenSource) WithHTTPClient(hc *http.Client) *HTTPTokenSource {
+	if s.HTTPClient != nil || hc == nil {
+		return s
+	}
+	return &HTTPTokenSource{
+		URL:         s.URL,
+		AppId:       s.AppId,
+		Secret:      s.Secret,
+		Header:      s.Header,
+		HTTPClient:  hc,
+		RenewBefore: s.RenewBefore,
+	}
+}
+
+func (s *HTTPTokenSource) Token(ctx context.Context) (string, error) {
+	at, err := s.AccessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	return at.AccessToken, nil
+}
+
+// AccessToken 获取 access_token 及其过期时间
+func (s *HTTPTokenSource) AccessToken(ctx context.Context) (*WxAccessToken, error) {
+	return s.cache.get(ctx, s.renewBefore(), s.fetch)
+}
+
+// RefreshToken 通知中控服务器 access_token 已失效并获取新的 access_token，实现 TokenRefresher
+func (s *HTTPTokenSource) RefreshToken(ctx context.Context, invalid string) (string, error) {
+	return s.cache.refresh(ctx, invalid, s.renewBefore(), s.fetch)
+}
+
+func (s *HTTPTokenSource) renewBefore() time.Duration {
+	if s.RenewBefore <= 0 {
+		return time.Minute
+	}
+	return s.RenewBefore
+}
+
+func (s *HTTPTokenSource) fetch(ctx context.Context, invalid string) (*WxAccessToken, error) {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return nil, fmt.Errorf("url.Parse(%s)：%w", s.URL, err)
+	}
+	query := u.Query()
+	if s.AppId != "" {
+		query.Set("appid", s.AppId)
+	}
+	if invalid != "" {
+		query.Set("invalid", invalid)
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range s.Header {
+		req.Header[k] = v
+	}
+	if s.Secret != "" {
+		if err = signTokenRequest(req, s.Secret, s.AppId, invalid); err != nil {
+			return nil, err
+		}
+	}
+
+	hc := s.HTTPClient
+	if hc == nil {
+		hc = xhttp.DefaultHttpClient
+	}
+	res, err := hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http.request(GET, %s)：%w", RedactURL(s.URL), RedactError(err))
+	}
+	defer res.Body.Close()
+
+	bs, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	at := &WxAccessToken{}
+	if err = json.Unmarshal(bs, at); err != nil {
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("StatusCode(%d) != 200", res.StatusCode)
+		}
+		// 不在错误中输出响应内容，避免泄露 access_token
+		return nil, fmt.Errorf("json.Unmarshal(%s)：%w", RedactURL(s.URL), err)
+	}
+	if at.ErrCode != 0 {
+		return nil, ToError(int64(at.ErrCode), at.ErrMsg)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("StatusCode(%d) != 200", res.StatusCode)
+	}
+	if at.AccessToken == "" {
+		return nil, errors.New("access_token is empty")
+	}
+	if at.ExpiresTime == 0 && at.ExpiresIn > 0 {
+		at.ExpiresTime = time.Now().Unix() + int64(at.ExpiresIn)
+	}
+
+	return at, nil
+}
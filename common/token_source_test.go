@@ -0,0 +1,123 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPTokenSource(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		if r.URL.Query().Get("invalid") == "token-1" {
+			w.Write([]byte(`{"access_token":"token-2","expires_in":7200}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"token-1","expires_in":7200}`))
+	}))
+	defer srv.Close()
+
+	transport := &countingTransport{}
+	origin := &HTTPTokenSource{URL: srv.URL, AppId: "wx_http_source"}
+	ts := origin.WithHTTPClient(&http.Client{Transport: transport})
+	if ts == origin || origin.HTTPClient != nil {
+		t.Fatal("WithHTTPClient modified the original source")
+	}
+
+	// 等待中的调用方按自己的 ctx 返回
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := ts.Token(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Token() with timeout = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if token, err := ts.Token(context.Background()); err != nil || token != "token-1" {
+				t.Errorf("Token() = %s, %v", token, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// 超时的调用方与并发的调用方合并为一次请求
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("fetch called %d times, want 1", n)
+	}
+	if n := atomic.LoadInt32(&transport.calls); n != 1 {
+		t.Fatalf("injected http.Client used %d times, want 1", n)
+	}
+
+	token, err := ts.RefreshToken(context.Background(), "token-1")
+	if err != nil || token != "token-2" {
+		t.Fatalf("RefreshToken() = %s, %v", token, err)
+	}
+	// 已经换成新的 access_token 时不再请求
+	if token, _ = ts.RefreshToken(context.Background(), "token-1"); token != "token-2" || atomic.LoadInt32(&calls) != 2 {
+		t.Fatalf("RefreshToken() = %s, calls = %d", token, calls)
+	}
+}
+
+func TestHTTPTokenSourceRenewBackoff(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			http.Error(w, "relay down", http.StatusBadGateway)
+			return
+		}
+		// 剩余时间小于 RenewBefore，每次获取都需要刷新
+		w.Write([]byte(`{"access_token":"token-1","expires_in":30}`))
+	}))
+	defer srv.Close()
+
+	ts := &HTTPTokenSource{URL: srv.URL, AppId: "wx_backoff"}
+	if token, err := ts.Token(context.Background()); err != nil || token != "token-1" {
+		t.Fatalf("Token() = %s, %v", token, err)
+	}
+
+	// 模拟 renewRetryInterval 已过，刷新失败时继续使用缓存
+	ts.cache.mu.Lock()
+	ts.cache.nextRenew = time.Time{}
+	ts.cache.mu.Unlock()
+	if token, err := ts.Token(context.Background()); err != nil || token != "token-1" {
+		t.Fatalf("Token() after failed renew = %s, %v", token, err)
+	}
+	// renewRetryInterval 内不再请求中控服务器
+	for i := 0; i < 5; i++ {
+		if token, err := ts.Token(context.Background()); err != nil || token != "token-1" {
+			t.Fatalf("Token() = %s, %v", token, err)
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("fetch called %d times, want 2", n)
+	}
+}
+
+func TestHTTPTokenSourceError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"SECRET_TOKEN",`))
+	}))
+	defer srv.Close()
+
+	ts := &HTTPTokenSource{URL: srv.URL, AppId: "wx_http_source"}
+	_, err := ts.Token(context.Background())
+	if err == nil {
+		t.Fatal("expected unmarshal error")
+	}
+	if strings.Contains(err.Error(), "SECRET_TOKEN") {
+		t.Fatalf("error leaks access_token: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TokenStore access_token 存储，多实例部署时替换为共享存储即可共用同一个 access_token
+type TokenStore interface {
+	// Get 获取 key 对应的值，不存在或已过期时 found 为 false
+	Get(ctx context.Context, key string) (value string, found bool, err error)
+	// Set 设置 key 的值，expiration 之后过期
+	Set(ctx context.Context, key, value string, expiration time.Duration) error
+	// Delete 删除 key
+	Delete(ctx context.Context, key string) error
+}
+
+// TokenLocker 可选实现，多实例同时刷新 access_token 时互斥，避免互相覆盖
+type TokenLocker interface {
+	// Lock 获取 key 对应的锁，ttl 之后锁自动失效，返回的 unlock 用于释放锁
+	Lock(ctx context.Context, key string, ttl time.Duration) (unlock func(), err error)
+}
+
+// AccessTokenKey access_token 在 TokenStore 中的 key
+func AccessTokenKey(appid string) string {
+	return fmt.Sprintf("%s_access_token", appid)
+}
+
+// LoadAccessToken 从 TokenStore 读取 access_token，不存在或已过期时返回 nil
+func LoadAccessToken(ctx context.Context, store TokenStore, appid string) (*WxAccessToken, error) {
+	value, found, err := store.Get(ctx, AccessTokenKey(appid))
+	if err != nil {
+		return nil, fmt.Errorf("token store get: %w", err)
+	}
+	if !found || value == "" {
+		return nil, nil
+	}
+
+	at := &WxAccessToken{}
+	if err = json.Unmarshal([]byte(value), at); err != nil {
+		// 不在错误中输出 value，避免泄露 access_token
+		return nil, fmt.Errorf("json.Unmarshal(%s)：%w", AccessTokenKey(appid), err)
+	}
+	if at.AccessToken == "" || (at.ExpiresTime > 0 && at.ExpiresTime <= time.Now().Unix()) {
+		return nil, nil
+	}
+
+	return at, nil
+}
+
+// SaveAccessToken 将 access_token 写入 TokenStore，过期时间与 access_token 一致
+func SaveAccessToken(ctx context.Context, store TokenStore, appid string, at *WxAccessToken) error {
+	bs, err := json.Marshal(at)
+	if err != nil {
+		return fmt.Errorf("json.Marshal(%+v)：%w", at, err)
+	}
+
+	expiration := time.Duration(at.ExpiresIn) * time.Second
+	if at.ExpiresTime > 0 {
+		expiration = time.Until(time.Unix(at.ExpiresTime, 0))
+	}
+	if expiration <= 0 {
+		return nil
+	}
+
+	if err = store.Set(ctx, AccessTokenKey(appid), string(bs), expiration); err != nil {
+		return fmt.Errorf("token store set: %w", err)
+	}
+	return nil
+}
+
+// MemoryTokenStore 进程内存储，仅适用于单实例部署，每个实例相互独立
+type MemoryTokenStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryTokenEntry
+}
+
+type memoryTokenEntry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// NewMemoryTokenStore 创建进程内存储
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		entries: make(map[string]memoryTokenEntry),
+	}
+}
+
+func (s *MemoryTokenStore) Get(ctx context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, found := s.entries[key]
+	if !found {
+		return "", false, nil
+	}
+	if !time.Now().Before(entry.expiresAt) {
+		delete(s.entries, key)
+		return "", false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (s *MemoryTokenStore) Set(ctx context.Context, key, value string, expiration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	// 顺带清理已过期的 key
+	for k, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = memoryTokenEntry{value: value, expiresAt: now.Add(expiration)}
+	return nil
+}
+
+func (s *MemoryTokenStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
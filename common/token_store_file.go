@@ -0,0 +1,155 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileTokenStore 文件存储，多个实例挂载同一目录（如 NFS、k8s 共享卷）即可共用 access_token
+type FileTokenStore struct {
+	dir          string
+	pollInterval time.Duration
+}
+
+type fileTokenEntry struct {
+	Value     string `json:"value"`
+	ExpiresAt int64  `json:"expires_at"` // 过期时间，unix 毫秒
+}
+
+// NewFileTokenStore 创建文件存储，dir 不存在时自动创建
+func NewFileTokenStore(dir string) (*FileTokenStore, error) {
+	if dir == "" {
+		return nil, errors.New("dir cannot be empty")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("os.MkdirAll(%s)：%w", dir, err)
+	}
+	return &FileTokenStore{
+		dir:          dir,
+		pollInterval: 50 * time.Millisecond,
+	}, nil
+}
+
+func (s *FileTokenStore) path(key, ext string) string {
+	return filepath.Join(s.dir, url.PathEscape(key)+ext)
+}
+
+func (s *FileTokenStore) Get(ctx context.Context, key string) (string, bool, error) {
+	bs, err := os.ReadFile(s.path(key, ".json"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	entry := &fileTokenEntry{}
+	if err = json.Unmarshal(bs, entry); err != nil {
+		// 不在错误中输出文件内容，避免泄露 access_token
+		return "", false, fmt.Errorf("json.Unmarshal(%s)：%w", key, err)
+	}
+	if time.Now().UnixMilli() >= entry.ExpiresAt {
+		return "", false, nil
+	}
+
+	return entry.Value, true, nil
+}
+
+// Set 先写临时文件再重命名，保证其他实例不会读到写了一半的内容
+func (s *FileTokenStore) Set(ctx context.Context, key, value string, expiration time.Duration) error {
+	bs, err := json.Marshal(&fileTokenEntry{
+		Value:     value,
+		ExpiresAt: time.Now().Add(expiration).UnixMilli(),
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(bs); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path(key, ".json"))
+}
+
+func (s *FileTokenStore) Delete(ctx context.Context, key string) error {
+	err := os.Remove(s.path(key, ".json"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Lock 通过独占创建锁文件实现互斥，锁文件中写入持有者的随机标识
+// 锁文件超过 ttl 视为持有者已退出，可被抢占；unlock 只删除自己持有的锁
+func (s *FileTokenStore) Lock(ctx context.Context, key string, ttl time.Duration) (func(), error) {
+	lockPath := s.path(key, ".lock")
+	owner, err := newNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_, err = f.WriteString(owner)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				s.removeLock(lockPath, owner, owner)
+				return nil, err
+			}
+			return func() { s.removeLock(lockPath, owner, owner) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > ttl {
+			// 只抢占检查过的那个锁，期间被其他实例替换的新锁不会被删除
+			if stale, readErr := os.ReadFile(lockPath); readErr == nil {
+				s.removeLock(lockPath, string(stale), owner)
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(s.pollInterval):
+		}
+	}
+}
+
+// removeLock 锁文件内容为 owner 时删除，self 为调用方的标识，用于生成不冲突的临时文件名
+// 先重命名再校验内容，保证检查与删除针对同一个文件；不是 owner 的锁时原样放回
+func (s *FileTokenStore) removeLock(lockPath, owner, self string) {
+	tmp := lockPath + "." + self + ".release"
+	if err := os.Rename(lockPath, tmp); err != nil {
+		return
+	}
+	defer os.Remove(tmp)
+
+	if bs, err := os.ReadFile(tmp); err == nil && string(bs) == owner {
+		return
+	}
+	// 放回时已有新的锁则放弃，os.Link 不会覆盖已存在的文件
+	os.Link(tmp, lockPath)
+}
@@ -0,0 +1,149 @@
+package common
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testTokenStore(t *testing.T, store TokenStore) {
+	ctx := context.Background()
+
+	if _, found, err := store.Get(ctx, "missing"); err != nil || found {
+		t.Fatalf("get missing: found=%v err=%v", found, err)
+	}
+
+	if err := store.Set(ctx, "key", "value", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if value, found, err := store.Get(ctx, "key"); err != nil || !found || value != "value" {
+		t.Fatalf("get: %q found=%v err=%v", value, found, err)
+	}
+
+	// 小于1秒的过期时间
+	if err := store.Set(ctx, "short", "value", 200*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := store.Get(ctx, "short"); !found {
+		t.Fatal("short ttl expired immediately")
+	}
+	time.Sleep(250 * time.Millisecond)
+	if _, found, _ := store.Get(ctx, "short"); found {
+		t.Fatal("short ttl not expired")
+	}
+
+	if err := store.Delete(ctx, "key"); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := store.Get(ctx, "key"); found {
+		t.Fatal("deleted key found")
+	}
+	if err := store.Delete(ctx, "key"); err != nil {
+		t.Fatalf("delete missing: %v", err)
+	}
+}
+
+func TestMemoryTokenStore(t *testing.T) {
+	testTokenStore(t, NewMemoryTokenStore())
+
+	// 实例之间相互独立
+	a, b := NewMemoryTokenStore(), NewMemoryTokenStore()
+	a.Set(context.Background(), "key", "value", time.Minute)
+	if _, found, _ := b.Get(context.Background(), "key"); found {
+		t.Fatal("memory stores share state")
+	}
+}
+
+func TestFileTokenStore(t *testing.T) {
+	store, err := NewFileTokenStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTokenStore(t, store)
+}
+
+func TestLoadAccessTokenError(t *testing.T) {
+	store := NewMemoryTokenStore()
+	store.Set(context.Background(), AccessTokenKey("wx_load"), `{"access_token":"SECRET_TOKEN"`, time.Minute)
+
+	_, err := LoadAccessToken(context.Background(), store, "wx_load")
+	if err == nil {
+		t.Fatal("expected unmarshal error")
+	}
+	if strings.Contains(err.Error(), "SECRET_TOKEN") {
+		t.Fatalf("error leaks token: %v", err)
+	}
+}
+
+func TestFileTokenStoreLock(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileTokenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.pollInterval = 5 * time.Millisecond
+	ctx := context.Background()
+
+	unlock, err := store.Lock(ctx, "key", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 已被持有时等待到 ctx 结束
+	timeoutCtx, cancel := context.WithTimeout(ctx, 30*time.Millisecond)
+	defer cancel()
+	if _, err := store.Lock(timeoutCtx, "key", time.Minute); err != context.DeadlineExceeded {
+		t.Fatalf("lock held: %v", err)
+	}
+
+	unlock()
+	unlock2, err := store.Lock(ctx, "key", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 重复 unlock 不会删除其他持有者的锁
+	unlock()
+	if _, err := os.Stat(filepath.Join(dir, "key.lock")); err != nil {
+		t.Fatalf("lock of another owner removed: %v", err)
+	}
+	unlock2()
+	if _, err := os.Stat(filepath.Join(dir, "key.lock")); !os.IsNotExist(err) {
+		t.Fatalf("lock not released: %v", err)
+	}
+}
+
+func TestFileTokenStoreStaleLock(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileTokenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.pollInterval = 5 * time.Millisecond
+
+	// 持有者退出后留下的锁
+	unlockStale, err := store.Lock(context.Background(), "key", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lockPath := filepath.Join(dir, "key.lock")
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(lockPath, old, old)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	unlock, err := store.Lock(ctx, "key", time.Minute)
+	if err != nil {
+		t.Fatalf("take over stale lock: %v", err)
+	}
+
+	// 原持有者恢复后 unlock 不会删除新的锁
+	unlockStale()
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Fatalf("new lock removed by stale owner: %v", err)
+	}
+	unlock()
+}
@@ -0,0 +1,110 @@
+// errcodegen 根据 data 目录下各产品线的错误码表生成 common/error_catalog.go
+//
+// 错误码表为 tsv 格式，每行为 错误码\t中文说明\t英文说明，# 开头的行为注释，文件名即产品线
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 产品线文件名 => common 包中的常量名
+var products = map[string]string{
+	"general": "ProductGeneral",
+	"mini":    "ProductMini",
+	"ocr":     "ProductOCR",
+	"open":    "ProductOpen",
+}
+
+type entry struct {
+	code   int64
+	zh, en string
+}
+
+func main() {
+	in := flag.String("in", "data", "错误码表目录")
+	out := flag.String("out", "error_catalog.go", "生成的文件")
+	flag.Parse()
+
+	files, err := filepath.Glob(filepath.Join(*in, "*.tsv"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	sort.Strings(files)
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("// Code generated by internal/errcodegen; DO NOT EDIT.\n\n")
+	buf.WriteString("package common\n\n")
+	buf.WriteString("var errCatalog = map[Product]map[int64]errText{\n")
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".tsv")
+		product, ok := products[name]
+		if !ok {
+			log.Fatalf("unknown product %s", file)
+		}
+		entries, err := parse(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintf(buf, "%s: {\n", product)
+		for _, e := range entries {
+			fmt.Fprintf(buf, "%d: {%s, %s},\n", e.code, strconv.Quote(e.zh), strconv.Quote(e.en))
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func parse(file string) ([]entry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []entry
+	seen := make(map[int64]bool)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: want 3 fields, got %d", file, line, len(fields))
+		}
+		code, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, line, err)
+		}
+		if seen[code] {
+			return nil, fmt.Errorf("%s:%d: duplicate code %d", file, line, code)
+		}
+		seen[code] = true
+		entries = append(entries, entry{code: code, zh: fields[1], en: fields[2]})
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].code < entries[j].code })
+	return entries, nil
+}
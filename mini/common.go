@@ -1,9 +1,11 @@
 package mini
 
+import "github.com/medreams/wechat/common"
+
 type SDK struct {
-	Appid       string
-	Secret      string
-	AccessToken string
+	Appid  string
+	Secret string
+	client *common.Client
 }
 
 type WxMiniQuota struct {
@@ -20,9 +22,19 @@ type WxMiniExpireParam struct {
 }
 
 func New(appid, secret, token string) *SDK {
+	return NewWithClient(appid, secret, common.NewClient(common.StaticTokenSource(token), common.WithAppId(appid)))
+}
+
+// NewWithClient 使用指定的请求客户端创建，access_token 由客户端的 TokenSource 在每次请求时提供
+func NewWithClient(appid, secret string, client *common.Client) *SDK {
 	return &SDK{
-		Appid:       appid,
-		Secret:      secret,
-		AccessToken: token,
+		Appid:  appid,
+		Secret: secret,
+		client: client,
 	}
 }
+
+// SetTokenSource 设置 access_token 来源，每次请求时从 ts 获取，例如从中控服务器获取
+func (sdk *SDK) SetTokenSource(ts common.TokenSource) {
+	sdk.client = sdk.client.WithTokenSource(ts)
+}
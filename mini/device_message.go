@@ -33,18 +33,10 @@ func (sdk *SDK) SendHardwareDeviceMessage(ctx context.Context, param *WxSendHard
 		bodyMap["miniprogram_state"] = param.MiniprogramState //小程序
 	}
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/message/device/subscribe/send?access_token=%s", sdk.AccessToken)
+	uri := "/cgi-bin/message/device/subscribe/send"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 type GetSnTicketParam struct {
@@ -63,16 +55,7 @@ func (sdk *SDK) GetSnTicket(ctx context.Context, param *GetSnTicketParam) (*GetS
 	bodyMap.Set("sn", param.Sn)
 	bodyMap.Set("model_id", param.ModelId)
 
-	req := &GetSnTicketRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxa/getsnticket?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/wxa/getsnticket"
 
-	return req, nil
+	return common.Call[GetSnTicketRsp](ctx, sdk.client, uri, bodyMap)
 }
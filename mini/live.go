@@ -2,7 +2,6 @@ package mini
 
 import (
 	"context"
-	"fmt"
 	"net/url"
 
 	"github.com/medreams/wechat/common"
@@ -41,18 +40,9 @@ type CreateRoomRsp struct {
 func (sdk *SDK) CreateRoom(ctx context.Context, param *CreateRoomParam) (*CreateRoomRsp, error) {
 	bodyMap := util.ConvertToMap(param)
 
-	req := &CreateRoomRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/create?access_token=%s", sdk.AccessToken)
+	uri := "/wxaapi/broadcast/room/create"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return req, nil
+	return common.Call[CreateRoomRsp](ctx, sdk.client, uri, bodyMap)
 }
 
 // 删除直播间 https://developers.weixin.qq.com/miniprogram/dev/OpenApiDoc/livebroadcast/studio-management/deleteRoom.html
@@ -60,18 +50,10 @@ func (sdk *SDK) DeleteRoom(ctx context.Context, id int) error {
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("id", id)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/deleteroom?access_token=%s", sdk.AccessToken)
+	uri := "/wxaapi/broadcast/room/deleteroom"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 type EditRoomParam struct {
@@ -98,18 +80,10 @@ type EditRoomParam struct {
 func (sdk *SDK) EditRoom(ctx context.Context, param *EditRoomParam) error {
 	bodyMap := util.ConvertToMap(param)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/editroom?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/wxaapi/broadcast/room/editroom"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 type ImportLiveGoodsParam struct {
@@ -123,18 +97,10 @@ func (sdk *SDK) ImportLiveGoods(ctx context.Context, param *ImportLiveGoodsParam
 	bodyMap.Set("ids", param.Ids)
 	bodyMap.Set("roomId", param.RoomId)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/addgoods?access_token=%s", sdk.AccessToken)
+	uri := "/wxaapi/broadcast/room/addgoods"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 推送商品 https://developers.weixin.qq.com/miniprogram/dev/OpenApiDoc/livebroadcast/studio-management/pushGoods.html
@@ -143,18 +109,10 @@ func (sdk *SDK) PushLiveGoods(ctx context.Context, roomId int, goodsId int) erro
 	bodyMap.Set("roomId", roomId)
 	bodyMap.Set("goodsId", goodsId)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/goods/push?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/wxaapi/broadcast/goods/push"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 上下架商品 https://developers.weixin.qq.com/miniprogram/dev/OpenApiDoc/livebroadcast/studio-management/SaleGoods.html
@@ -164,18 +122,10 @@ func (sdk *SDK) SaleLiveGoods(ctx context.Context, roomId int, goodsId int, onSa
 	bodyMap.Set("goodsId", goodsId)
 	bodyMap.Set("onSale", onSale)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/goods/onsale?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/wxaapi/broadcast/goods/onsale"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 直播间商品排序 https://developers.weixin.qq.com/miniprogram/dev/OpenApiDoc/livebroadcast/studio-management/sortGoods.html
@@ -192,18 +142,10 @@ func (sdk *SDK) SortLiveGoods(ctx context.Context, roomId int, goodsIds []int) e
 	bodyMap.Set("roomId", roomId)
 	bodyMap.Set("goodsId", goods)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/goods/sort?access_token=%s", sdk.AccessToken)
+	uri := "/wxaapi/broadcast/goods/sort"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 删除直播间商品 https://developers.weixin.qq.com/miniprogram/dev/OpenApiDoc/livebroadcast/studio-management/deleteDoods.html
@@ -212,18 +154,10 @@ func (sdk *SDK) DelLiveGoods(ctx context.Context, roomId int, goodsId int) error
 	bodyMap.Set("roomId", roomId)
 	bodyMap.Set("goodsId", goodsId)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/goods/deleteInRoom?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/wxaapi/broadcast/goods/deleteInRoom"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 type GetRoomeListParam struct {
@@ -279,18 +213,14 @@ type RoomInfo struct {
 func (sdk *SDK) GetLiveInfo(ctx context.Context, param *GetRoomeListParam) (*RoomeListRsp, error) {
 	bodyMap := util.ConvertToMap(param)
 
-	req := &RoomeListRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxa/business/getliveinfo?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
+	uri := "/wxa/business/getliveinfo"
 
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	return common.Call[RoomeListRsp](ctx, sdk.client, uri, bodyMap)
+}
 
-	return req, nil
+type LivePushUrlRsp struct {
+	common.WxCommonResponse
+	PushAddr string `json:"pushAddr"`
 }
 
 // 获取直播间推流地址 https://developers.weixin.qq.com/miniprogram/dev/OpenApiDoc/livebroadcast/studio-management/getPushUrl.html
@@ -298,19 +228,11 @@ func (sdk *SDK) GetPushUrl(ctx context.Context, roomId int) (string, error) {
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("roomId", roomId)
 
-	var req struct {
-		ErrCode  int64  `json:"errcode"` //错误码
-		PushAddr string `json:"pushAddr"`
-	}
-
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/getpushurl?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, &req); err != nil {
-		return "", fmt.Errorf("do request: %w", err)
-	}
+	uri := "/wxaapi/broadcast/room/getpushurl"
 
-	if req.ErrCode != 0 {
-		return "", fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
+	req, err := common.Call[LivePushUrlRsp](ctx, sdk.client, uri, bodyMap)
+	if err != nil {
+		return "", err
 	}
 
 	return req.PushAddr, nil
@@ -330,18 +252,9 @@ func (sdk *SDK) GetLiveSharedCode(ctx context.Context, roomId string, params str
 	bodyMap.Set("roomId", roomId)                  //房间ID
 	bodyMap.Set("params", url.QueryEscape(params)) //自定义参数
 
-	req := &LiveSharedCodeRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/getpushurl?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/wxaapi/broadcast/room/getpushurl"
 
-	return req, nil
+	return common.Call[LiveSharedCodeRsp](ctx, sdk.client, uri, bodyMap)
 }
 
 // 添架主播副号 https://developers.weixin.qq.com/miniprogram/dev/OpenApiDoc/livebroadcast/studio-management/addSubAnchor.html
@@ -350,18 +263,10 @@ func (sdk *SDK) CreateLiveSubAnchor(ctx context.Context, roomId int, username st
 	bodyMap.Set("roomId", roomId)
 	bodyMap.Set("username", username)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/addsubanchor?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/wxaapi/broadcast/room/addsubanchor"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 修改主播副号 https://developers.weixin.qq.com/miniprogram/dev/OpenApiDoc/livebroadcast/studio-management/modifySubAnchor.html
@@ -370,18 +275,15 @@ func (sdk *SDK) EditLiveSubAnchor(ctx context.Context, roomId int, username stri
 	bodyMap.Set("roomId", roomId)
 	bodyMap.Set("username", username)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/modifysubanchor?access_token=%s", sdk.AccessToken)
+	uri := "/wxaapi/broadcast/room/modifysubanchor"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
+}
 
-	return nil
+type LiveSubAnchorRsp struct {
+	common.WxCommonResponse
+	Username string `json:"username"`
 }
 
 // 获取主播副号 https://developers.weixin.qq.com/miniprogram/dev/OpenApiDoc/livebroadcast/studio-management/getSubAnchor.html
@@ -389,19 +291,11 @@ func (sdk *SDK) GetLiveSubAnchor(ctx context.Context, roomId int) (string, error
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("roomId", roomId)
 
-	var req struct {
-		ErrCode  int64  `json:"errcode"` //错误码
-		Username string `json:"username"`
-	}
-
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/getsubanchor?access_token=%s", sdk.AccessToken)
+	uri := "/wxaapi/broadcast/room/getsubanchor"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, &req); err != nil {
-		return "", fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return "", fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
+	req, err := common.Call[LiveSubAnchorRsp](ctx, sdk.client, uri, bodyMap)
+	if err != nil {
+		return "", err
 	}
 
 	return req.Username, nil
@@ -412,18 +306,10 @@ func (sdk *SDK) DelLiveSubAnchor(ctx context.Context, roomId int) error {
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("roomId", roomId)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/deletesubanchor?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/wxaapi/broadcast/room/deletesubanchor"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 type LiveRoomeAssistant struct {
@@ -437,18 +323,10 @@ func (sdk *SDK) CreateLiveAssistant(ctx context.Context, roomId int, users []Liv
 	bodyMap.Set("roomId", roomId)
 	bodyMap.Set("users", users)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/addassistant?access_token=%s", sdk.AccessToken)
+	uri := "/wxaapi/broadcast/room/addassistant"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 修改直播间小助手 https://developers.weixin.qq.com/miniprogram/dev/OpenApiDoc/livebroadcast/studio-management/modifyAssistant.html
@@ -458,18 +336,10 @@ func (sdk *SDK) EditLiveAssistant(ctx context.Context, roomId int, user LiveRoom
 	bodyMap.Set("username", user.Username)
 	bodyMap.Set("nickname", user.Nickname)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/modifyassistant?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/wxaapi/broadcast/room/modifyassistant"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 删除直播间小助手 https://developers.weixin.qq.com/miniprogram/dev/OpenApiDoc/livebroadcast/studio-management/removeAssistant.html
@@ -478,25 +348,18 @@ func (sdk *SDK) DelLiveAssistant(ctx context.Context, roomId int, username strin
 	bodyMap.Set("roomId", roomId)
 	bodyMap.Set("username", username)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/removeassistant?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/wxaapi/broadcast/room/removeassistant"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 type LiveRoomeAssistantListRsp struct {
 	List     []LiveRoomeAssistantList `json:"list"`     //小助手列表
 	Count    int                      `json:"count"`    //小助手个数
 	MaxCount int                      `json:"maxCount"` //小助手最大个数
-	ErrCode  int                      `json:"errcode"`  //返回码
+	ErrCode  int64                    `json:"errcode"`  //返回码
+	ErrMsg   string                   `json:"errmsg"`   //错误信息
 }
 
 type LiveRoomeAssistantList struct {
@@ -512,18 +375,9 @@ func (sdk *SDK) GetLiveAssistant(ctx context.Context, roomId int) (*LiveRoomeAss
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("roomId", roomId)
 
-	req := &LiveRoomeAssistantListRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/getassistantlist?access_token=%s", sdk.AccessToken)
+	uri := "/wxaapi/broadcast/room/getassistantlist"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return req, nil
+	return common.Call[LiveRoomeAssistantListRsp](ctx, sdk.client, uri, bodyMap)
 }
 
 // 禁言管理(整个直播间) https://developers.weixin.qq.com/miniprogram/dev/OpenApiDoc/livebroadcast/studio-management/updateComment.html
@@ -532,18 +386,10 @@ func (sdk *SDK) UpdateLiveRomeComment(ctx context.Context, roomId int, banCommen
 	bodyMap.Set("id", roomId)             //房间ID
 	bodyMap.Set("banComment", banComment) //1-禁言，0-取消禁言
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/updatecomment?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/wxaapi/broadcast/room/updatecomment"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 官方收录管理 https://developers.weixin.qq.com/miniprogram/dev/OpenApiDoc/livebroadcast/studio-management/updateFeedPublic.html
@@ -552,18 +398,10 @@ func (sdk *SDK) UpdateLiveRoomeFeedPublic(ctx context.Context, roomId int, isFee
 	bodyMap.Set("id", roomId)
 	bodyMap.Set("isFeedsPublic", isFeedsPublic) //是否开启官方收录 【1: 开启，0：关闭】
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/updatefeedpublic?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/wxaapi/broadcast/room/updatefeedpublic"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 客服功能管理 https://developers.weixin.qq.com/miniprogram/dev/OpenApiDoc/livebroadcast/studio-management/updateKF.html
@@ -572,18 +410,10 @@ func (sdk *SDK) UpdateLiveRoomeKf(ctx context.Context, roomId int, closeKf int)
 	bodyMap.Set("id", roomId)
 	bodyMap.Set("closeKf", closeKf) //是否关闭客服 【0：开启，1：关闭】
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/updatekf?access_token=%s", sdk.AccessToken)
+	uri := "/wxaapi/broadcast/room/updatekf"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 回放功能管理 https://developers.weixin.qq.com/miniprogram/dev/OpenApiDoc/livebroadcast/studio-management/updateReplay.html
@@ -592,18 +422,15 @@ func (sdk *SDK) UpdateLiveRoomeReplay(ctx context.Context, roomId int, closeRepl
 	bodyMap.Set("id", roomId)
 	bodyMap.Set("closeReplay", closeReplay) //是否关闭回放 【0：开启，1：关闭】
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/room/updatereplay?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
+	uri := "/wxaapi/broadcast/room/updatereplay"
 
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
+}
 
-	return nil
+type GoodsVideoRsp struct {
+	common.WxCommonResponse
+	Url string `json:"url"`
 }
 
 // 下载商品讲解视频 https://developers.weixin.qq.com/miniprogram/dev/OpenApiDoc/livebroadcast/studio-management/downloadGoodsVideo.html
@@ -612,18 +439,11 @@ func (sdk *SDK) DownloadGoodsVideo(ctx context.Context, roomId int, goodsId int)
 	bodyMap.Set("id", roomId)
 	bodyMap.Set("goodsId", goodsId) //商品ID
 
-	var req struct {
-		ErrCode int64  `json:"errcode"` //错误码
-		Url     string `json:"url"`
-	}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/broadcast/goods/getVideo?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return "", fmt.Errorf("do request: %w", err)
-	}
+	uri := "/wxaapi/broadcast/goods/getVideo"
 
-	if req.ErrCode != 0 {
-		return "", fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
+	req, err := common.Call[GoodsVideoRsp](ctx, sdk.client, uri, bodyMap)
+	if err != nil {
+		return "", err
 	}
 
 	return req.Url, nil
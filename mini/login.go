@@ -15,41 +15,26 @@ type WxCode2Session struct {
 }
 
 func (sdk *SDK) Code2Session(c context.Context, code string) (req *WxCode2Session, err error) {
+	uri := fmt.Sprintf("/sns/jscode2session?appid=%s&secret=%s&js_code=%s&grant_type=authorization_code", sdk.Appid, sdk.Secret, code)
 
-	req = &WxCode2Session{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/sns/jscode2session?appid=%s&secret=%s&js_code=%s&grant_type=authorization_code", sdk.Appid, sdk.Secret, code)
-
-	if err = common.DoRequestGet(c, uri, req); err != nil {
-		return nil, fmt.Errorf("do request get session: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("get session error: %d, %s", req.ErrCode, req.ErrMsg)
-	}
-
-	return req, nil
+	return common.CallGet[WxCode2Session](c, sdk.client.WithoutToken(), uri)
 
 }
 
 // 新版本的code获取手机号码，收费接口，需要开通
 // <button open-type="getPhoneNumber" bindgetphonenumber="getPhoneNumber"></button>
 func (sdk *SDK) Code2Phone(c context.Context, code string) (phone *WxUserPhone, err error) {
-	req := &struct {
-		common.WxCommonResponse
-		Phone WxUserPhone `json:"phone_info,omitempty"`
-	}{}
-
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("code", code)
 
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxa/business/getuserphonenumber?access_token=%s", sdk.AccessToken)
-
-	if err = common.DoRequestPost(c, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request get phone: %w", err)
-	}
+	uri := "/wxa/business/getuserphonenumber"
 
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("get phone error: %d, %s", req.ErrCode, req.ErrMsg)
+	req, err := common.Call[struct {
+		common.WxCommonResponse
+		Phone WxUserPhone `json:"phone_info,omitempty"`
+	}](c, sdk.client, uri, bodyMap)
+	if err != nil {
+		return nil, err
 	}
 
 	return &req.Phone, nil
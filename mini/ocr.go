@@ -2,7 +2,6 @@ package mini
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/medreams/wechat/common"
 )
@@ -30,14 +29,9 @@ func (sdk *SDK) OcrVehicleLicense(ctx context.Context, imgUrl string) (req *Vehi
 	bodyMap := make(common.BodyMap)
 	bodyMap["img_url"] = imgUrl
 
-	req = &VehicleLicenseData{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cv/ocr/driving?access_token=%s", sdk.AccessToken)
+	uri := "/cv/ocr/driving"
 
-	if err = common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request get phone: %w", err)
-	}
-
-	return req, nil
+	return common.Call[VehicleLicenseData](ctx, sdk.client, uri, bodyMap)
 }
 
 type BankCardData struct {
@@ -50,14 +44,9 @@ func (sdk *SDK) OcrBankCard(ctx context.Context, imgUrl string) (bc *BankCardDat
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("img_url", imgUrl)
 
-	bc = &BankCardData{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cv/ocr/bankcard?access_token=%s", sdk.AccessToken)
-
-	if err = common.DoRequestPost(ctx, uri, bodyMap, bc); err != nil {
-		return nil, fmt.Errorf("do request get phone: %w", err)
-	}
+	uri := "/cv/ocr/bankcard"
 
-	return bc, nil
+	return common.Call[BankCardData](ctx, sdk.client, uri, bodyMap)
 }
 
 type BusinessLicenseData struct {
@@ -80,14 +69,9 @@ func (sdk *SDK) OcrBusinessLicense(ctx context.Context, imgUrl string) (*Busines
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("img_url", imgUrl)
 
-	req := &BusinessLicenseData{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cv/ocr/bizlicense?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request get phone: %w", err)
-	}
+	uri := "/cv/ocr/bizlicense"
 
-	return req, nil
+	return common.Call[BusinessLicenseData](ctx, sdk.client, uri, bodyMap)
 }
 
 type DriverLicenseData struct {
@@ -109,14 +93,9 @@ func (sdk *SDK) OcrDriverLicense(ctx context.Context, imgUrl string) (*DriverLic
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("img_url", imgUrl)
 
-	req := &DriverLicenseData{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cv/ocr/bizlicense?access_token=%s", sdk.AccessToken)
+	uri := "/cv/ocr/bizlicense"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request get phone: %w", err)
-	}
-
-	return req, nil
+	return common.Call[DriverLicenseData](ctx, sdk.client, uri, bodyMap)
 }
 
 type IdCardData struct {
@@ -135,12 +114,7 @@ func (sdk *SDK) OcrIdCard(ctx context.Context, imgUrl string) (*IdCardData, erro
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("img_url", imgUrl)
 
-	req := &IdCardData{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cv/ocr/idcard?type=photo&access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request get phone: %w", err)
-	}
+	uri := "/cv/ocr/idcard?type=photo"
 
-	return req, nil
+	return common.Call[IdCardData](ctx, sdk.client, uri, bodyMap)
 }
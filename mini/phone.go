@@ -23,16 +23,19 @@ func (sdk *SDK) MiniCodeGetPhone(ctx context.Context, phoneCode string) (*WxUser
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("code", phoneCode)
 
-	req := &WxUserPhone{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxa/business/getuserphonenumber?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request get phone: %w", err)
+	uri := "/wxa/business/getuserphonenumber"
+
+	req, err := common.Call[struct {
+		common.WxCommonResponse
+		Phone WxUserPhone `json:"phone_info,omitempty"`
+	}](ctx, sdk.client, uri, bodyMap)
+	if err != nil {
+		return nil, err
 	}
 
-	if req.Watermark.Appid != sdk.Appid {
+	if req.Phone.Watermark == nil || req.Phone.Watermark.Appid != sdk.Appid {
 		return nil, fmt.Errorf("get phone error: %s", "appid not match")
 	}
 
-	return req, nil
+	return &req.Phone, nil
 }
@@ -3,7 +3,6 @@ package mini
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 
 	"github.com/medreams/wechat/common"
 )
@@ -34,8 +33,18 @@ type ReturnMiniCodeData struct {
 	ImageBase64 string `form:"image_base64" json:"image_base64"` //图片的base64格式
 }
 
-// CreateMiniSceneCode 创建小程序二维码（较多业务场景）
-func (sdk *SDK) CreateMiniSceneCode(ctx context.Context, param *WxMiniSceneParam) ([]byte, error) {
+// CreateMiniSceneCode 创建小程序二维码（较多业务场景），失败时返回 *common.APIError
+func (sdk *SDK) CreateMiniSceneCode(ctx context.Context, param *WxMiniSceneParam) (*common.Image, error) {
+	stream, err := sdk.CreateMiniSceneCodeStream(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.ReadImage(stream)
+}
+
+// CreateMiniSceneCodeStream 创建小程序二维码（较多业务场景），以流的方式返回图片，读取后需要 Close
+func (sdk *SDK) CreateMiniSceneCodeStream(ctx context.Context, param *WxMiniSceneParam) (*common.Stream, error) {
 	//log.Info.Println("创建小程序二维码（较多业务场景）", param)
 
 	if param.LineColor == "" {
@@ -57,28 +66,38 @@ func (sdk *SDK) CreateMiniSceneCode(ctx context.Context, param *WxMiniSceneParam
 	bodyMap.Set("is_hyaline", param.IsHyaline) //是否需要透明底色
 	bodyMap.Set("line_color", lineColorMap)
 
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxa/getwxacodeunlimit?access_token=%s", sdk.AccessToken)
+	uri := "/wxa/getwxacodeunlimit"
+
+	stream, err := sdk.client.DoRequestPostStream(ctx, uri, bodyMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return stream, nil
+}
 
-	bs, err := common.DoRequestPostByte(ctx, uri, bodyMap)
+// CreateMiniDefaultCode 创建小程序二维码（较少业务场景），失败时返回 *common.APIError
+func (sdk *SDK) CreateMiniDefaultCode(ctx context.Context, param *WxMiniPathParam) (*common.Image, error) {
+	stream, err := sdk.CreateMiniDefaultCodeStream(ctx, param)
 	if err != nil {
 		return nil, err
 	}
 
-	return bs, nil
+	return common.ReadImage(stream)
 }
 
-// CreateMiniDefaultCode 创建小程序二维码（较少业务场景）
-func (sdk *SDK) CreateMiniDefaultCode(ctx context.Context, param *WxMiniPathParam) ([]byte, error) {
+// CreateMiniDefaultCodeStream 创建小程序二维码（较少业务场景），以流的方式返回图片，读取后需要 Close
+func (sdk *SDK) CreateMiniDefaultCodeStream(ctx context.Context, param *WxMiniPathParam) (*common.Stream, error) {
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("path", param.Path)
 	bodyMap.Set("width", param.Width)
 
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/wxaapp/createwxaqrcode?access_token=%s", sdk.AccessToken)
+	uri := "/cgi-bin/wxaapp/createwxaqrcode"
 
-	bs, err := common.DoRequestPostByte(ctx, uri, bodyMap)
+	stream, err := sdk.client.DoRequestPostStream(ctx, uri, bodyMap)
 	if err != nil {
 		return nil, err
 	}
 
-	return bs, nil
+	return stream, nil
 }
@@ -2,7 +2,6 @@ package mini
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/medreams/wechat/common"
 )
@@ -18,16 +17,7 @@ func (sdk *SDK) GetWxShortLink(ctx context.Context, pageUrl, title string, isPee
 	bodyMap.Set("page_title", title)
 	bodyMap.Set("is_permanent", isPeermanent)
 
-	req := &WxShortLink{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxa/genwxashortlink?access_token=%s", sdk.AccessToken)
+	uri := "/wxa/genwxashortlink"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request get wxShortLink: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("get wxShortLink error: %d, %s", req.ErrCode, req.ErrMsg)
-	}
-
-	return req, nil
+	return common.Call[WxShortLink](ctx, sdk.client, uri, bodyMap)
 }
@@ -2,7 +2,6 @@ package mini
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/medreams/wechat/common"
 )
@@ -62,26 +61,16 @@ func (sdk *SDK) GenerateUrlLink(ctx context.Context, path, query, envVersion str
 		bodyMap.Set("cloud_base", *cb)
 	}
 
-	req := &WxMiniLink{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxa/generate_urllink?access_token=%s", sdk.AccessToken)
+	uri := "/wxa/generate_urllink"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request get wxMiniLink: %w", err)
-	}
-
-	return req, nil
+	return common.Call[WxMiniLink](ctx, sdk.client, uri, bodyMap)
 
 }
 func (sdk *SDK) QueryUrlLink(ctx context.Context, urlLink string) (*WxMiniUrlLinkQuery, error) {
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("url_link", urlLink)
 
-	req := &WxMiniUrlLinkQuery{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxa/query_urllink?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request get wxMiniUrlLinkQuery: %w", err)
-	}
+	uri := "/wxa/query_urllink"
 
-	return req, nil
+	return common.Call[WxMiniUrlLinkQuery](ctx, sdk.client, uri, bodyMap)
 }
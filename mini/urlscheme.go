@@ -2,7 +2,6 @@ package mini
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/medreams/wechat/common"
 )
@@ -51,26 +50,16 @@ func (sdk *SDK) GenerateScheme(ctx context.Context, expire *WxMiniExpireParam, j
 		bodyMap.Set("expire_interval", 30)
 	}
 
-	req := &WxMiniScheme{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxa/generatescheme?access_token=%s", sdk.AccessToken)
+	uri := "/wxa/generatescheme"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request get wxMiniScheme: %w", err)
-	}
-
-	return req, nil
+	return common.Call[WxMiniScheme](ctx, sdk.client, uri, bodyMap)
 
 }
 func (sdk *SDK) QueryScheme(ctx context.Context, scheme string) (*WxMiniSchemeQuery, error) {
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("scheme", scheme)
 
-	req := &WxMiniSchemeQuery{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxa/queryscheme?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request get wxMiniSchemeQuery: %w", err)
-	}
+	uri := "/wxa/queryscheme"
 
-	return req, nil
+	return common.Call[WxMiniSchemeQuery](ctx, sdk.client, uri, bodyMap)
 }
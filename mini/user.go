@@ -13,16 +13,11 @@ type PaidUnionId struct {
 }
 
 func (sdk *SDK) GetPaidUnionId(c context.Context, openid string) (unionId string, err error) {
+	uri := fmt.Sprintf("/wxa/getpaidunionid?openid=%s", openid)
 
-	req := &PaidUnionId{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxa/getpaidunionid?access_token=%s&openid=%s", sdk.AccessToken, openid)
-
-	if err = common.DoRequestGet(c, uri, req); err != nil {
-		return "", fmt.Errorf("do request get unionId: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return "", fmt.Errorf("get unionId error: %d, %s", req.ErrCode, req.ErrMsg)
+	req, err := common.CallGet[PaidUnionId](c, sdk.client, uri)
+	if err != nil {
+		return "", err
 	}
 
 	return req.Unionid, nil
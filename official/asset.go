@@ -2,6 +2,7 @@ package official
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/medreams/wechat/common"
@@ -26,6 +27,34 @@ const (
 	MediaTypeNews MediaType = "news"
 )
 
+// 各类型素材的大小限制，上传前校验，大小未知时由微信校验
+var mediaSizeLimit = map[MediaType]int64{
+	MediaTypeImage:     10 << 20, // bmp/png/jpeg/jpg/gif
+	MediaTypeVoice:     2 << 20,  // amr/mp3，播放长度不超过60s
+	MediaTypeVideo:     10 << 20, // mp4
+	MediaTypeThumb:     64 << 10, // jpg
+	MediaTypeNewsImage: 1 << 20,  // jpg/png
+}
+
+// ErrMediaTooLarge 素材超过微信限制的大小：图片、视频10MB，语音2MB，缩略图64KB，图文内图片1MB
+var ErrMediaTooLarge = errors.New("media too large")
+
+// 校验素材大小
+func checkMediaSize(fileType MediaType, file *util.File) error {
+	limit, ok := mediaSizeLimit[fileType]
+	if !ok {
+		return nil
+	}
+	size, err := file.Len()
+	if err != nil {
+		return err
+	}
+	if size > limit {
+		return fmt.Errorf("%w: %s %s is %d bytes, limit %d bytes", ErrMediaTooLarge, fileType, file.Name, size, limit)
+	}
+	return nil
+}
+
 type UploadTempAssetsRsp struct {
 	common.WxCommonResponse
 	Type      string `json:"type"`       //媒体文件类型，分别有图片（image）、语音（voice）、视频（video）和缩略图（thumb，主要用于视频与音乐格式的缩略图）
@@ -34,25 +63,18 @@ type UploadTempAssetsRsp struct {
 }
 
 // 上传临时素材 媒体文件在微信后台保存时间为3天，即3天后media_id失效 https://developers.weixin.qq.com/doc/offiaccount/Asset_Management/New_temporary_materials.html
+// file 可以使用 util.NewFileFromPath、util.NewFileFromReader 流式上传大文件
 func (sdk *SDK) UploadTempAssets(ctx context.Context, fileType MediaType, file *util.File) (*UploadTempAssetsRsp, error) {
+	if err := checkMediaSize(fileType, file); err != nil {
+		return nil, err
+	}
 
 	bodyMap := make(common.BodyMap)
 	bodyMap.SetFormFile("media", file)
 
-	fmt.Printf("bm : %#+v\n", bodyMap)
+	uri := fmt.Sprintf("/cgi-bin/media/upload?type=%s", fileType)
 
-	req := &UploadTempAssetsRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/media/upload?access_token=%s&type=%s", sdk.AccessToken, fileType)
-
-	if err := common.DoUploadFile(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return req, nil
+	return common.CallUpload[UploadTempAssetsRsp](ctx, sdk.client, uri, bodyMap)
 }
 
 type GetAssetsRsp struct {
@@ -62,19 +84,21 @@ type GetAssetsRsp struct {
 
 // 获取临时素材 https://developers.weixin.qq.com/doc/offiaccount/Asset_Management/Get_temporary_materials.html
 func (sdk *SDK) GetTempAssets(ctx context.Context, mediaId string) (*GetAssetsRsp, error) {
+	uri := fmt.Sprintf("/cgi-bin/media/get?media_id=%s", mediaId)
 
-	req := &GetAssetsRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/media/get?access_token=%s&media_id=%s", sdk.AccessToken, mediaId)
+	return common.CallGet[GetAssetsRsp](ctx, sdk.client, uri)
+}
 
-	if err := common.DoRequestGet(ctx, uri, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
+// 下载临时素材文件，读取后需要 Close；视频素材返回的是 json，请使用 GetTempAssets
+func (sdk *SDK) DownloadTempAssets(ctx context.Context, mediaId string) (*common.Stream, error) {
+	uri := fmt.Sprintf("/cgi-bin/media/get?media_id=%s", mediaId)
 
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
+	stream, err := sdk.client.DoRequestGetStream(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
 	}
 
-	return req, nil
+	return stream, nil
 }
 
 type UploadPermanentAssetsRsp struct {
@@ -90,16 +114,20 @@ type VideDescription struct {
 }
 
 // 上传永久图片素材 https://developers.weixin.qq.com/doc/offiaccount/Asset_Management/Adding_Permanent_Assets.html
+// file 可以使用 util.NewFileFromPath、util.NewFileFromReader 流式上传大文件
 func (sdk *SDK) UploadPermanentAssets(ctx context.Context, fileType MediaType, file *util.File, vd *VideDescription) (*UploadPermanentAssetsRsp, error) {
+	if err := checkMediaSize(fileType, file); err != nil {
+		return nil, err
+	}
 	bodyMap := make(common.BodyMap)
 	bodyMap.SetFormFile("media", file)
 	uri := ""
 	if fileType == MediaTypeNewsImage {
 		//上传图文消息内的图片
-		uri = fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/media/uploadimg?access_token=%s", sdk.AccessToken)
+		uri = "/cgi-bin/media/uploadimg"
 	} else {
 		//新增其他类型永久素材
-		uri = fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/material/add_material?access_token=%s&type=%s", sdk.AccessToken, fileType)
+		uri = fmt.Sprintf("/cgi-bin/material/add_material?type=%s", fileType)
 
 		if fileType == MediaTypeVideo {
 			bodyMap.SetBodyMap("description", func(b common.BodyMap) {
@@ -108,16 +136,7 @@ func (sdk *SDK) UploadPermanentAssets(ctx context.Context, fileType MediaType, f
 			})
 		}
 	}
-	req := &UploadPermanentAssetsRsp{}
-	if err := common.DoUploadFile(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return req, nil
+	return common.CallUpload[UploadPermanentAssetsRsp](ctx, sdk.client, uri, bodyMap)
 }
 
 type GetMaterialRsp struct {
@@ -145,18 +164,24 @@ func (sdk *SDK) GetPermanentAssets(ctx context.Context, mediaId string) (*GetMat
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("media_id", mediaId)
 
-	req := &GetMaterialRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/material/get_material?access_token=%s", sdk.AccessToken)
+	uri := "/cgi-bin/material/get_material"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
+	return common.Call[GetMaterialRsp](ctx, sdk.client, uri, bodyMap)
+}
+
+// 下载永久素材文件，读取后需要 Close；图文、视频素材返回的是 json，请使用 GetPermanentAssets
+func (sdk *SDK) DownloadPermanentAssets(ctx context.Context, mediaId string) (*common.Stream, error) {
+	bodyMap := make(common.BodyMap)
+	bodyMap.Set("media_id", mediaId)
+
+	uri := "/cgi-bin/material/get_material"
 
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
+	stream, err := sdk.client.DoRequestPostStream(ctx, uri, bodyMap)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
 	}
 
-	return req, nil
+	return stream, nil
 }
 
 // 删除永久素材 https://developers.weixin.qq.com/doc/offiaccount/Asset_Management/Deleting_Permanent_Assets.html
@@ -164,18 +189,10 @@ func (sdk *SDK) DelPermanentAssets(ctx context.Context, mediaId string) error {
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("media_id", mediaId)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/material/del_material?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/cgi-bin/material/del_material"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 type GetPermanentAssetsTotalRsp struct {
@@ -188,19 +205,9 @@ type GetPermanentAssetsTotalRsp struct {
 
 // 获取素材总数 https://developers.weixin.qq.com/doc/offiaccount/Asset_Management/Get_the_total_of_all_materials.html
 func (sdk *SDK) GetPermanentAssetsTotal(ctx context.Context) (*GetPermanentAssetsTotalRsp, error) {
+	uri := "/cgi-bin/material/get_materialcount"
 
-	req := &GetPermanentAssetsTotalRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/material/get_materialcount?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestGet(ctx, uri, req); err != nil {
-		return req, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return req, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return req, nil
+	return common.CallGet[GetPermanentAssetsTotalRsp](ctx, sdk.client, uri)
 }
 
 type PermanentAssetsListRsp struct {
@@ -241,16 +248,7 @@ func (sdk *SDK) GetPermanentAssetsList(ctx context.Context, mediaType MediaType,
 	bodyMap.Set("offset", offset)
 	bodyMap.Set("count", count)
 
-	req := &PermanentAssetsListRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/material/batchget_material?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/cgi-bin/material/batchget_material"
 
-	return req, nil
+	return common.Call[PermanentAssetsListRsp](ctx, sdk.client, uri, bodyMap)
 }
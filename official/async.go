@@ -0,0 +1,238 @@
+package official
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAsyncWorkers 后台同时执行的 handler 数量
+	DefaultAsyncWorkers = 10
+	// DefaultAsyncQueueSize 等待执行的消息数量
+	DefaultAsyncQueueSize = 100
+	// DefaultAsyncTimeout 后台执行 handler 的超时时间
+	DefaultAsyncTimeout = time.Minute
+
+	// 发送客服消息的超时时间
+	asyncSendTimeout = 10 * time.Second
+)
+
+// ErrAsyncReplierClosed AsyncReplier 已关闭
+var ErrAsyncReplierClosed = errors.New("async replier closed")
+
+// ReplySender 将超时后 handler 返回的回复发送给用户，通常为 SDK.SendCustomerMessage
+type ReplySender func(ctx context.Context, openid string, msg *ReplyMessage) error
+
+// AsyncConfig 后台处理配置，为0的字段使用默认值
+type AsyncConfig struct {
+	Workers   int           // 同时执行的 handler 数量，默认 DefaultAsyncWorkers
+	QueueSize int           // 等待执行的消息数量，队列满时丢弃消息并回复 success，默认 DefaultAsyncQueueSize
+	Timeout   time.Duration // 后台执行 handler 的超时时间，默认 DefaultAsyncTimeout
+	Logger    *slog.Logger  // 记录后台 handler 的错误，默认使用 slog.Default()
+}
+
+// AsyncReplier 在有限的 worker 中执行 handler
+// 超过被动回复时间的 handler 继续在后台执行，其回复通过 ReplySender 以客服消息发送
+type AsyncReplier struct {
+	send    ReplySender
+	timeout time.Duration
+	logger  *slog.Logger
+
+	tasks chan *asyncTask
+	quit  chan struct{}
+	wg    sync.WaitGroup
+
+	// 关闭后取消仍在执行的 handler
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewAsyncReplier 创建并启动 worker，不再使用时调用 Close
+func NewAsyncReplier(send ReplySender, cfg AsyncConfig) *AsyncReplier {
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultAsyncWorkers
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultAsyncQueueSize
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultAsyncTimeout
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &AsyncReplier{
+		send:    send,
+		timeout: cfg.Timeout,
+		logger:  cfg.Logger,
+		tasks:   make(chan *asyncTask, cfg.QueueSize),
+		quit:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	a.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go a.work()
+	}
+	return a
+}
+
+// Close 停止接收新消息，等待队列中的消息处理完成
+// ctx 结束时取消仍在执行的 handler 并返回 ctx.Err()
+func (a *AsyncReplier) Close(ctx context.Context) error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return ErrAsyncReplierClosed
+	}
+	a.closed = true
+	close(a.quit)
+	a.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		a.cancel()
+		return nil
+	case <-ctx.Done():
+		a.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// 放入队列，队列已满或已关闭时返回 false
+func (a *AsyncReplier) submit(t *asyncTask) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return false
+	}
+	select {
+	case a.tasks <- t:
+		return true
+	default:
+		return false
+	}
+}
+
+func (a *AsyncReplier) work() {
+	defer a.wg.Done()
+	for {
+		select {
+		case t := <-a.tasks:
+			a.run(t)
+		case <-a.quit:
+			// 处理完队列中剩余的消息
+			for {
+				select {
+				case t := <-a.tasks:
+					a.run(t)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (a *AsyncReplier) run(t *asyncTask) {
+	// 不随请求结束而取消，保留请求 ctx 中的值
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(t.ctx), a.timeout)
+	defer cancel()
+	stop := context.AfterFunc(a.ctx, cancel)
+	defer stop()
+
+	res := serveMessage(ctx, t.handler, t.msg)
+	if !t.finish(res) {
+		return
+	}
+
+	// 已回复 success，结果通过客服消息发送
+	openid := t.msg.FromUserName
+	if res.err != nil {
+		a.logger.Error("wechat async message handler failed", "msgtype", t.msg.MsgType, "event", t.msg.Event, "openid", openid, "error", res.err)
+		return
+	}
+	if res.reply == nil || res.reply.MsgType == "" {
+		return
+	}
+
+	// Close 超时后同样取消发送
+	sendCtx, sendCancel := context.WithTimeout(context.WithoutCancel(t.ctx), asyncSendTimeout)
+	defer sendCancel()
+	stopSend := context.AfterFunc(a.ctx, sendCancel)
+	defer stopSend()
+	if err := a.send(sendCtx, openid, res.reply); err != nil {
+		a.logger.Error("wechat async reply send failed", "msgtype", res.reply.MsgType, "openid", openid, "error", err)
+	}
+}
+
+type asyncTask struct {
+	ctx     context.Context
+	handler MessageHandler
+	msg     *ReceivingMessage
+
+	done chan handleResult // 容量为1
+
+	mu        sync.Mutex
+	abandoned bool
+}
+
+func newAsyncTask(ctx context.Context, handler MessageHandler, msg *ReceivingMessage) *asyncTask {
+	return &asyncTask{
+		ctx:     ctx,
+		handler: handler,
+		msg:     msg,
+		done:    make(chan handleResult, 1),
+	}
+}
+
+// handler 返回后调用，请求已放弃等待时返回 true，由调用方发送客服消息
+func (t *asyncTask) finish(res handleResult) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.abandoned {
+		return true
+	}
+	t.done <- res
+	return false
+}
+
+// 等待超时后调用，handler 恰好已返回时 ok 为 true
+func (t *asyncTask) abandon() (res handleResult, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	select {
+	case res = <-t.done:
+		return res, true
+	default:
+		t.abandoned = true
+		return res, false
+	}
+}
+
+// 执行 handler 并恢复 panic
+func serveMessage(ctx context.Context, handler MessageHandler, msg *ReceivingMessage) (res handleResult) {
+	defer func() {
+		if e := recover(); e != nil {
+			res = handleResult{err: fmt.Errorf("panic: %v", e)}
+		}
+	}()
+	reply, err := handler.ServeMessage(ctx, msg)
+	return handleResult{reply: reply, err: err}
+}
@@ -2,7 +2,6 @@ package official
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/medreams/wechat/common"
 )
@@ -14,14 +13,11 @@ type GetApiDomainIpRsp struct {
 
 // 获取微信服务器 IP 地址  https://developers.weixin.qq.com/doc/offiaccount/Basic_Information/Get_the_WeChat_server_IP_address.html
 func (sdk *SDK) GetApiDomainIp(ctx context.Context) ([]string, error) {
-	req := &GetApiDomainIpRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/get_api_domain_ip?access_token=%s", sdk.AccessToken)
+	uri := "/cgi-bin/get_api_domain_ip"
 
-	if err := common.DoRequestGet(ctx, uri, req); err != nil {
-		return nil, fmt.Errorf("do request get access_token: %w", err)
-	}
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
+	req, err := common.CallGet[GetApiDomainIpRsp](ctx, sdk.client, uri)
+	if err != nil {
+		return nil, err
 	}
 
 	return req.IPList, nil
@@ -29,14 +25,11 @@ func (sdk *SDK) GetApiDomainIp(ctx context.Context) ([]string, error) {
 
 // 获取微信callback IP地址
 func (sdk *SDK) GetCallbackDomainIp(ctx context.Context) ([]string, error) {
-	req := &GetApiDomainIpRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/getcallbackip?access_token=%s", sdk.AccessToken)
+	uri := "/cgi-bin/getcallbackip"
 
-	if err := common.DoRequestGet(ctx, uri, req); err != nil {
-		return nil, fmt.Errorf("do request get access_token: %w", err)
-	}
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
+	req, err := common.CallGet[GetApiDomainIpRsp](ctx, sdk.client, uri)
+	if err != nil {
+		return nil, err
 	}
 
 	return req.IPList, nil
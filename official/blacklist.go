@@ -3,7 +3,6 @@ package official
 import (
 	"context"
 	"errors"
-	"fmt"
 
 	"github.com/medreams/wechat/common"
 )
@@ -13,13 +12,9 @@ func (sdk *SDK) GetUserBlackList(ctx context.Context, beginOpenid string) (list
 	bodyMap := make(common.BodyMap)
 	bodyMap["begin_openid"] = beginOpenid
 
-	list = &UserOpenidList{}
-	uri := "https://api.weixin.qq.com/cgi-bin/tags/members/getblacklist?access_token=" + sdk.AccessToken
-	if err = common.DoRequestPost(ctx, uri, bodyMap, list); err != nil {
-		return nil, fmt.Errorf("do request get user usertag id list: %w", err)
-	}
+	uri := "/cgi-bin/tags/members/getblacklist"
 
-	return list, nil
+	return common.Call[UserOpenidList](ctx, sdk.client, uri, bodyMap)
 }
 
 // 拉黑用户(一次20个)
@@ -32,12 +27,9 @@ func (sdk *SDK) AddUsersToBlackList(ctx context.Context, openids []string) (rst
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("openid_list", openids)
 
-	uri := "https://api.weixin.qq.com/cgi-bin/tags/members/batchblacklist?access_token=" + sdk.AccessToken
-	if err = common.DoRequestPost(ctx, uri, bodyMap, rst); err != nil {
-		return nil, fmt.Errorf("do request get user usertag id list: %w", err)
-	}
+	uri := "/cgi-bin/tags/members/batchblacklist"
 
-	return rst, nil
+	return common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
 }
 
 // 取消拉黑用户(一次20个)
@@ -50,10 +42,7 @@ func (sdk *SDK) CancelUsersFromBlackList(ctx context.Context, openids []string)
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("openid_list", openids)
 
-	uri := "https://api.weixin.qq.com/cgi-bin/tags/members/batchunblacklist?access_token=" + sdk.AccessToken
-	if err = common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request get user usertag id list: %w", err)
-	}
+	uri := "/cgi-bin/tags/members/batchunblacklist"
 
-	return req, nil
+	return common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
 }
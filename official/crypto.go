@@ -0,0 +1,96 @@
+package official
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/medreams/wechat/pkg/util"
+)
+
+// ErrInvalidMsgSignature 安全模式下 msg_signature 校验失败
+var ErrInvalidMsgSignature = errors.New("invalid msg_signature")
+
+// 安全模式下推送的消息
+type encryptedMessage struct {
+	XMLName    xml.Name `xml:"xml"`
+	ToUserName string   `xml:"ToUserName"`
+	Encrypt    string   `xml:"Encrypt"`
+}
+
+// EncryptedReply 安全模式下回复的消息
+type EncryptedReply struct {
+	XMLName      xml.Name `xml:"xml"`
+	Encrypt      CDATA    `xml:"Encrypt"`
+	MsgSignature CDATA    `xml:"MsgSignature"`
+	TimeStamp    int64    `xml:"TimeStamp"`
+	Nonce        CDATA    `xml:"Nonce"`
+}
+
+// MessageCrypto 兼容模式、安全模式下消息的加解密
+type MessageCrypto struct {
+	appid          string
+	token          string
+	encodingAESKey string
+}
+
+// NewMessageCrypto token、encodingAESKey 为服务器配置中的令牌(Token)和消息加解密密钥(EncodingAESKey)
+func NewMessageCrypto(appid, token, encodingAESKey string) *MessageCrypto {
+	return &MessageCrypto{
+		appid:          appid,
+		token:          token,
+		encodingAESKey: encodingAESKey,
+	}
+}
+
+// Decrypt 校验 msg_signature 并解密推送的消息，返回明文 xml
+func (c *MessageCrypto) Decrypt(msgSignature, timestamp, nonce string, body []byte) ([]byte, error) {
+	msg := &encryptedMessage{}
+	if err := xml.Unmarshal(body, msg); err != nil {
+		return nil, err
+	}
+	if msg.Encrypt == "" {
+		return nil, errors.New("encrypt is empty")
+	}
+	if !CheckMsgSignature(c.token, msgSignature, timestamp, nonce, msg.Encrypt) {
+		return nil, ErrInvalidMsgSignature
+	}
+
+	_, plaintext, err := util.DecryptMsg(c.appid, msg.Encrypt, c.encodingAESKey)
+	if err != nil {
+		return nil, err
+	}
+	return plaintext, nil
+}
+
+// Encrypt 加密回复的明文 xml 并签名
+func (c *MessageCrypto) Encrypt(plaintext []byte) (*EncryptedReply, error) {
+	encrypt, err := util.EncryptMsg([]byte(util.RandomString(16)), plaintext, c.appid, c.encodingAESKey)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt message: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	nonce := util.RandomString(16)
+	return &EncryptedReply{
+		Encrypt:      CDATA(encrypt),
+		MsgSignature: CDATA(sign(c.token, strconv.FormatInt(timestamp, 10), nonce, string(encrypt))),
+		TimeStamp:    timestamp,
+		Nonce:        CDATA(nonce),
+	}, nil
+}
+
+// MarshalEncrypted 加密后序列化回复消息，用于安全模式
+func (rm *ReplyMessage) MarshalEncrypted(c *MessageCrypto) ([]byte, error) {
+	plaintext, err := xml.Marshal(rm)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := c.Encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return xml.Marshal(reply)
+}
@@ -28,19 +28,9 @@ type CustomerList struct {
 
 // 客服列表
 func (sdk *SDK) GetCustomerList(ctx context.Context) (*CustomerList, error) {
+	uri := "/cgi-bin/customservice/getkflist"
 
-	req := &CustomerList{}
-
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/customservice/getkflist?access_token=%s", sdk.AccessToken)
-	if err := common.DoRequestGet(ctx, uri, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, common.ToError(req.ErrCode, req.ErrMsg)
-	}
-
-	return req, nil
+	return common.CallGet[CustomerList](ctx, sdk.client, uri)
 }
 
 type CustomerOnlineInfo struct {
@@ -57,18 +47,9 @@ type CustomerOnlineList struct {
 
 // 在线客服列表
 func (sdk *SDK) GetOnlineCustomerList(ctx context.Context) (*CustomerOnlineList, error) {
+	uri := "/cgi-bin/customservice/getonlinekflist"
 
-	req := &CustomerOnlineList{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/customservice/getonlinekflist?access_token=%s", sdk.AccessToken)
-	if err := common.DoRequestGet(ctx, uri, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, common.ToError(req.ErrCode, req.ErrMsg)
-	}
-
-	return req, nil
+	return common.CallGet[CustomerOnlineList](ctx, sdk.client, uri)
 }
 
 // 添加客服帐号
@@ -77,17 +58,10 @@ func (sdk *SDK) CreateCustomer(ctx context.Context, account, nickname string) er
 	bodyMap.Set("kf_account", account)
 	bodyMap.Set("nickname", nickname)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/customservice/kfaccount/add?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
+	uri := "/customservice/kfaccount/add"
 
-	if req.ErrCode != 0 {
-		return common.ToError(req.ErrCode, req.ErrMsg)
-	}
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 邀请绑定客服帐号
@@ -96,17 +70,10 @@ func (sdk *SDK) InviteCustomer(ctx context.Context, account, inviteWx string) er
 	bodyMap.Set("kf_account", account)
 	bodyMap.Set("invite_wx", inviteWx)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/customservice/kfaccount/inviteworker?access_token=%s", sdk.AccessToken)
+	uri := "/customservice/kfaccount/inviteworker"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return common.ToError(req.ErrCode, req.ErrMsg)
-	}
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 设置客服昵称
@@ -115,17 +82,10 @@ func (sdk *SDK) UpdateCustomerNickname(ctx context.Context, account, nickname st
 	bodyMap.Set("kf_account", account)
 	bodyMap.Set("nickname", nickname)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/customservice/kfaccount/update?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
+	uri := "/customservice/kfaccount/update"
 
-	if req.ErrCode != 0 {
-		return common.ToError(req.ErrCode, req.ErrMsg)
-	}
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 上传客服头像
@@ -133,32 +93,18 @@ func (sdk *SDK) UploadCustomerHeadimg(ctx context.Context, account string, headi
 	bodyMap := make(common.BodyMap)
 	bodyMap.SetFormFile("media", headimg)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/customservice/kfaccount/uploadheadimg?access_token=%s&kf_account=%s", sdk.AccessToken, account)
-
-	if err := common.DoUploadFile(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
+	uri := fmt.Sprintf("/customservice/kfaccount/uploadheadimg?kf_account=%s", account)
 
-	if req.ErrCode != 0 {
-		return common.ToError(req.ErrCode, req.ErrMsg)
-	}
-	return nil
+	_, err := common.CallUpload[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 删除客服帐号
 func (sdk *SDK) DeleteCustomer(ctx context.Context, account string) error {
+	uri := fmt.Sprintf("/customservice/kfaccount/del?kf_account=%s", account)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/customservice/kfaccount/del?access_token=%s&kf_account=%s", sdk.AccessToken, account)
-	if err := common.DoRequestGet(ctx, uri, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return common.ToError(req.ErrCode, req.ErrMsg)
-	}
-	return nil
+	_, err := common.CallGet[common.WxCommonResponse](ctx, sdk.client, uri)
+	return err
 }
 
 // 创建会话
@@ -167,17 +113,10 @@ func (sdk *SDK) CreateCustomerSession(ctx context.Context, account, openid strin
 	bodyMap.Set("kf_account", account)
 	bodyMap.Set("openid", openid)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/customservice/kfsession/create?access_token=%s", sdk.AccessToken)
+	uri := "/customservice/kfsession/create"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return common.ToError(req.ErrCode, req.ErrMsg)
-	}
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 关闭会话
@@ -186,17 +125,10 @@ func (sdk *SDK) CloseCustomerSession(ctx context.Context, account, openid string
 	bodyMap.Set("kf_account", account)
 	bodyMap.Set("openid", openid)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/customservice/kfsession/close?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
+	uri := "/customservice/kfsession/close"
 
-	if req.ErrCode != 0 {
-		return common.ToError(req.ErrCode, req.ErrMsg)
-	}
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 type CustomerSessionStatus struct {
@@ -207,18 +139,9 @@ type CustomerSessionStatus struct {
 
 // 获取客户会话状态
 func (sdk *SDK) GetCustomerSession(ctx context.Context, openid string) (*CustomerSessionStatus, error) {
+	uri := "/customservice/kfsession/getsession"
 
-	req := &CustomerSessionStatus{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/customservice/kfsession/getsession?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestGet(ctx, uri, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, common.ToError(req.ErrCode, req.ErrMsg)
-	}
-	return req, nil
+	return common.CallGet[CustomerSessionStatus](ctx, sdk.client, uri)
 }
 
 type CustomerSessionList struct {
@@ -233,18 +156,9 @@ type CustomerSession struct {
 
 // 获取客服会话列表
 func (sdk *SDK) GetCustomerSessionList(ctx context.Context, account string) (*CustomerSessionList, error) {
+	uri := "/customservice/kfsession/getsessionlist"
 
-	req := &CustomerSessionList{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/customservice/kfsession/getsessionlist?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestGet(ctx, uri, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, common.ToError(req.ErrCode, req.ErrMsg)
-	}
-	return req, nil
+	return common.CallGet[CustomerSessionList](ctx, sdk.client, uri)
 }
 
 type CustomerWaitSessionList struct {
@@ -260,18 +174,9 @@ type CustomerWaitSession struct {
 
 // 获取未接入会话列表
 func (sdk *SDK) GetCustomerWaitSessionList(ctx context.Context, account string) (*CustomerWaitSessionList, error) {
+	uri := "/customservice/kfsession/getwaitcase"
 
-	req := &CustomerWaitSessionList{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/customservice/kfsession/getwaitcase?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestGet(ctx, uri, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, common.ToError(req.ErrCode, req.ErrMsg)
-	}
-	return req, nil
+	return common.CallGet[CustomerWaitSessionList](ctx, sdk.client, uri)
 }
 
 type CustomerMsgList struct {
@@ -296,15 +201,80 @@ func (sdk *SDK) GetCustomerMsg(ctx context.Context, starttime, endtime, msgid, n
 	bodyMap.Set("msgid", msgid)
 	bodyMap.Set("number", number)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/customservice/msgrecord/getmsglist?access_token=%s", sdk.AccessToken)
+	uri := "/customservice/msgrecord/getmsglist"
+
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
+}
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
+// 发送客服消息，msg 使用被动回复消息的结构，用户48小时内与公众号有过互动才能发送
+// https://developers.weixin.qq.com/doc/offiaccount/Message_Management/Service_Center_messages.html
+func (sdk *SDK) SendCustomerMessage(ctx context.Context, openid string, msg *ReplyMessage) error {
+	bodyMap, err := customerMessageBody(openid, msg)
+	if err != nil {
+		return err
 	}
 
-	if req.ErrCode != 0 {
-		return common.ToError(req.ErrCode, req.ErrMsg)
+	uri := "/cgi-bin/message/custom/send"
+
+	_, err = common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
+}
+
+// 被动回复消息转为客服消息的请求体
+func customerMessageBody(openid string, msg *ReplyMessage) (common.BodyMap, error) {
+	msgType := string(msg.MsgType)
+
+	bodyMap := make(common.BodyMap)
+	bodyMap.Set("touser", openid)
+	bodyMap.Set("msgtype", msgType)
+
+	switch {
+	case msgType == "text" && msg.Content != nil:
+		bodyMap.SetBodyMap("text", func(b common.BodyMap) {
+			b.Set("content", string(*msg.Content))
+		})
+	case msgType == "image" && msg.Image != nil:
+		bodyMap.SetBodyMap("image", func(b common.BodyMap) {
+			b.Set("media_id", string(msg.Image.MediaId))
+		})
+	case msgType == "voice" && msg.Voice != nil:
+		bodyMap.SetBodyMap("voice", func(b common.BodyMap) {
+			b.Set("media_id", string(msg.Voice.MediaId))
+		})
+	case msgType == "video" && msg.Video != nil:
+		bodyMap.SetBodyMap("video", func(b common.BodyMap) {
+			b.Set("media_id", string(msg.Video.MediaId))
+			if msg.Video.Title != nil {
+				b.Set("title", string(*msg.Video.Title))
+			}
+			if msg.Video.Description != nil {
+				b.Set("description", string(*msg.Video.Description))
+			}
+		})
+	case msgType == "music" && msg.Music != nil:
+		bodyMap.SetBodyMap("music", func(b common.BodyMap) {
+			b.Set("title", string(msg.Music.Title))
+			b.Set("description", string(msg.Music.Description))
+			b.Set("musicurl", string(msg.Music.MusicUrl))
+			b.Set("hqmusicurl", string(msg.Music.HQMusicUrl))
+			b.Set("thumb_media_id", string(msg.Music.ThumbMediaId))
+		})
+	case msgType == "news" && msg.Articles != nil:
+		articles := make([]common.BodyMap, 0, len(msg.Articles.Item))
+		for _, item := range msg.Articles.Item {
+			articles = append(articles, common.BodyMap{
+				"title":       item.Title,
+				"description": item.Description,
+				"url":         item.URL,
+				"picurl":      item.PicUrl,
+			})
+		}
+		bodyMap.SetBodyMap("news", func(b common.BodyMap) {
+			b.Set("articles", articles)
+		})
+	default:
+		return nil, fmt.Errorf("unsupported customer message type %q", msgType)
 	}
-	return nil
+	return bodyMap, nil
 }
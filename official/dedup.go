@@ -0,0 +1,107 @@
+package official
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultDedupTTL 微信5秒内收不到响应会重试3次，记录保留1分钟
+const DefaultDedupTTL = time.Minute
+
+// DedupStore 记录已处理的消息，多实例部署时可以使用 redis SETNX 等实现
+type DedupStore interface {
+	// Claim 消息在 ttl 内第一次出现时记录并返回 true，重复的消息返回 false
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Release 删除记录，处理失败时调用，微信重试推送时可以重新处理
+	Release(ctx context.Context, key string) error
+}
+
+// MemoryDedupStore 进程内记录已处理的消息，只适用于单实例部署，过期的记录在 Claim 时清理
+// 不使用 pkg/cache：它是进程内单例，多个 Server 会共用同一份记录
+type MemoryDedupStore struct {
+	mu        sync.Mutex
+	expires   map[string]time.Time // key => 过期时间
+	lastSweep time.Time
+}
+
+// NewMemoryDedupStore 创建进程内去重记录，每个实例相互独立
+func NewMemoryDedupStore() *MemoryDedupStore {
+	return &MemoryDedupStore{
+		expires:   make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryDedupStore) Claim(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	// 每过 ttl 清理一次，记录数不超过 ttl 内收到的消息数的两倍
+	if now.Sub(s.lastSweep) >= ttl {
+		for k, expiresAt := range s.expires {
+			if !now.Before(expiresAt) {
+				delete(s.expires, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if expiresAt, found := s.expires[key]; found && now.Before(expiresAt) {
+		return false, nil
+	}
+	s.expires[key] = now.Add(ttl)
+	return true, nil
+}
+
+func (s *MemoryDedupStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.expires, key)
+	return nil
+}
+
+// MessageKey 消息去重的 key，普通消息使用 MsgId，事件使用 FromUserName+CreateTime
+func MessageKey(msg *ReceivingMessage) string {
+	if msg.MsgId != "" {
+		return "wechat:dedup:" + msg.ToUserName + ":" + msg.MsgId
+	}
+	return "wechat:dedup:" + msg.ToUserName + ":" + msg.FromUserName + ":" + msg.CreateTime + ":" + msg.Event
+}
+
+// Dedup 消息去重中间件，重复推送的消息回复 success 不再处理
+// handler 返回错误、panic 时删除记录，微信重试推送时重新处理
+// store 为 nil 时使用 NewMemoryDedupStore，ttl 不大于0时使用 DefaultDedupTTL；store 出错时继续处理消息
+func Dedup(store DedupStore, ttl time.Duration) Middleware {
+	if store == nil {
+		store = NewMemoryDedupStore()
+	}
+	if ttl <= 0 {
+		ttl = DefaultDedupTTL
+	}
+	return func(next MessageHandler) MessageHandler {
+		return MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (reply *ReplyMessage, err error) {
+			key := MessageKey(msg)
+			first, claimErr := store.Claim(ctx, key, ttl)
+			if claimErr != nil {
+				return next.ServeMessage(ctx, msg)
+			}
+			if !first {
+				return nil, nil
+			}
+
+			succeeded := false
+			defer func() {
+				if !succeeded {
+					// 超时时 ctx 已取消，删除记录不受影响
+					store.Release(context.WithoutCancel(ctx), key)
+				}
+			}()
+			reply, err = next.ServeMessage(ctx, msg)
+			succeeded = err == nil
+			return reply, err
+		})
+	}
+}
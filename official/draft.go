@@ -31,18 +31,9 @@ func (sdk *SDK) CreateDraft(ctx context.Context, param []*Article) (*AddDraftRsp
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("articles", param)
 
-	req := &AddDraftRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/draft/add?access_token=%s", sdk.AccessToken)
+	uri := "/cgi-bin/draft/add"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, common.ToError(req.ErrCode, req.ErrMsg)
-	}
-
-	return req, nil
+	return common.Call[AddDraftRsp](ctx, sdk.client, uri, bodyMap)
 }
 
 type GetDraftRsp struct {
@@ -55,18 +46,9 @@ func (sdk *SDK) GetDraft(ctx context.Context, mediaId string) (*GetDraftRsp, err
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("media_id", mediaId)
 
-	req := &GetDraftRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/draft/get?access_token=%s", sdk.AccessToken)
+	uri := "/cgi-bin/draft/get"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, common.ToError(req.ErrCode, req.ErrMsg)
-	}
-
-	return req, nil
+	return common.Call[GetDraftRsp](ctx, sdk.client, uri, bodyMap)
 }
 
 // 删除草稿 https://developers.weixin.qq.com/doc/offiaccount/Draft_Box/Delete_draft.html
@@ -74,18 +56,10 @@ func (sdk *SDK) DelDraft(ctx context.Context, mediaId string) error {
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("media_id", mediaId)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/draft/delete?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return common.ToError(req.ErrCode, req.ErrMsg)
-	}
+	uri := "/cgi-bin/draft/delete"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 修改草稿 https://developers.weixin.qq.com/doc/offiaccount/Draft_Box/Update_draft.html
@@ -95,18 +69,10 @@ func (sdk *SDK) UpdateDraft(ctx context.Context, mediaId string, index int, arti
 	bodyMap.Set("index", index)
 	bodyMap.Set("articles", article)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/draft/update?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return common.ToError(req.ErrCode, req.ErrMsg)
-	}
+	uri := "/cgi-bin/draft/update"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 type GetDraftTotalRsp struct {
@@ -116,16 +82,9 @@ type GetDraftTotalRsp struct {
 
 // 获取草稿总数 https://developers.weixin.qq.com/doc/offiaccount/Draft_Box/Count_drafts.html
 func (sdk *SDK) GetDraftTotal(ctx context.Context) (*GetDraftTotalRsp, error) {
-	req := &GetDraftTotalRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/draft/count?access_token=%s", sdk.AccessToken)
-	if err := common.DoRequestGet(ctx, uri, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
+	uri := "/cgi-bin/draft/count"
 
-	if req.ErrCode != 0 {
-		return nil, common.ToError(req.ErrCode, req.ErrMsg)
-	}
-	return req, nil
+	return common.CallGet[GetDraftTotalRsp](ctx, sdk.client, uri)
 }
 
 type GetDraftListRsp struct {
@@ -148,18 +107,9 @@ func (sdk *SDK) GetDraftList(ctx context.Context, offset, count, noContent int)
 	bodyMap.Set("count", count)          //返回素材的数量，取值在1到20之间
 	bodyMap.Set("no_content", noContent) //1 表示不返回 content 字段，0 表示正常返回，默认为 0
 
-	req := &GetDraftRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/draft/batchget?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
+	uri := "/cgi-bin/draft/batchget"
 
-	if req.ErrCode != 0 {
-		return nil, common.ToError(req.ErrCode, req.ErrMsg)
-	}
-
-	return req, nil
+	return common.Call[GetDraftRsp](ctx, sdk.client, uri, bodyMap)
 }
 
 type DraftSwitchRsp struct {
@@ -169,19 +119,10 @@ type DraftSwitchRsp struct {
 
 // MP端开关（仅内测期间使用）https://developers.weixin.qq.com/doc/offiaccount/Draft_Box/Temporary_MP_Switch.html
 func (sdk *SDK) MpDraftSwitch(ctx context.Context, checkonly int) (*DraftSwitchRsp, error) {
-	req := &DraftSwitchRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/draft/switch?access_token=%s", sdk.AccessToken)
+	uri := "/cgi-bin/draft/switch"
 	if checkonly == 1 {
-		uri = fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/draft/switch?access_token=%s&checkonly=%d", sdk.AccessToken, checkonly)
-	}
-
-	if err := common.DoRequestPost(ctx, uri, nil, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, common.ToError(req.ErrCode, req.ErrMsg)
+		uri = fmt.Sprintf("/cgi-bin/draft/switch?checkonly=%d", checkonly)
 	}
 
-	return req, nil
+	return common.Call[DraftSwitchRsp, common.BodyMap](ctx, sdk.client, uri, nil)
 }
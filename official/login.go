@@ -21,15 +21,11 @@ type WxWebAccessToekn struct {
 
 // 网页授权和开放平台网页code获取access_token(此access_token,只能在网页授权和开放平台网页中使用)
 func (sdk *SDK) Code2WebAccessToken(ctx context.Context, code string) (req *WxWebAccessToekn, err error) {
-	req = &WxWebAccessToekn{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/sns/oauth2/access_token?appid=%s&secret=%s&code=%s&grant_type=authorization_code", sdk.Appid, sdk.Secret, code)
+	uri := fmt.Sprintf("/sns/oauth2/access_token?appid=%s&secret=%s&code=%s&grant_type=authorization_code", sdk.Appid, sdk.Secret, code)
 
-	if err = common.DoRequestGet(ctx, uri, req); err != nil {
-		return nil, fmt.Errorf("do request get access_token: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("get access_token error: %d, %s", req.ErrCode, req.ErrMsg)
+	req, err = common.CallGet[WxWebAccessToekn](ctx, sdk.client.WithoutToken(), uri)
+	if err != nil {
+		return nil, err
 	}
 
 	req.ExpiresTime = time.Now().Unix() + int64(req.ExpiresIn)
@@ -39,15 +35,11 @@ func (sdk *SDK) Code2WebAccessToken(ctx context.Context, code string) (req *WxWe
 }
 
 func (sdk *SDK) RefreshAccessToken(ctx context.Context, refreshToken string) (req *WxWebAccessToekn, err error) {
-	req = &WxWebAccessToekn{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/sns/oauth2/refresh_token?grant_type=refresh_token&appid=%s&refresh_token=%s", sdk.Appid, refreshToken)
-
-	if err = common.DoRequestGet(ctx, uri, req); err != nil {
-		return nil, fmt.Errorf("do request get access_token: %w", err)
-	}
+	uri := fmt.Sprintf("/sns/oauth2/refresh_token?grant_type=refresh_token&appid=%s&refresh_token=%s", sdk.Appid, refreshToken)
 
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("get access_token error: %d, %s", req.ErrCode, req.ErrMsg)
+	req, err = common.CallGet[WxWebAccessToekn](ctx, sdk.client.WithoutToken(), uri)
+	if err != nil {
+		return nil, err
 	}
 
 	req.ExpiresTime = time.Now().Unix() + int64(req.ExpiresIn)
@@ -57,15 +49,9 @@ func (sdk *SDK) RefreshAccessToken(ctx context.Context, refreshToken string) (re
 
 // 公众号网页授权获取的access_token拉取用户信息
 func (sdk *SDK) WebAccessTokenAndOpenid2UserInfo(ctx context.Context, webAccessToken string, openid string) (req *UserInfo, err error) {
-	req = &UserInfo{}
+	uri := fmt.Sprintf("/sns/userinfo?access_token=%s&openid=%s&lang=zh_CN", webAccessToken, openid)
 
-	uri := fmt.Sprintf("https://api.weixin.qq.com/sns/userinfo?access_token=%s&openid=%s&lang=zh_CN", webAccessToken, openid)
-
-	if err = common.DoRequestGet(ctx, uri, req); err != nil {
-		return nil, fmt.Errorf("do request get mp userinfo: %w", err)
-	}
-
-	return req, nil
+	return common.CallGet[UserInfo](ctx, sdk.client.WithoutToken(), uri)
 }
 
 // 公众号网页获取授权code
@@ -2,7 +2,6 @@ package official
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/medreams/wechat/common"
 	"github.com/medreams/wechat/pkg/util"
@@ -34,18 +33,10 @@ type MenuButton struct {
 func (sdk *SDK) CreateCustomMenu(ctx context.Context, param *CreateMenuParams) error {
 	bodyMap := util.ConvertToMap(param)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/menu/create?access_token=%s", sdk.AccessToken)
+	uri := "/cgi-bin/menu/create"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 type GetMenuRsp struct {
@@ -70,28 +61,15 @@ type GetMenuInfo struct {
 
 // 查询自定义菜单
 func (sdk *SDK) QueryCustomMenu(ctx context.Context) (*GetMenuRsp, error) {
-	req := &GetMenuRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/get_current_selfmenu_info?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, nil, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
+	uri := "/cgi-bin/get_current_selfmenu_info"
 
-	return req, nil
+	return common.Call[GetMenuRsp, common.BodyMap](ctx, sdk.client, uri, nil)
 }
 
 // 删除自定义菜单（调用此接口会删除默认菜单及全部个性化菜单）
 func (sdk *SDK) DelCustomMenu(ctx context.Context) error {
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/menu/delete?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, nil, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/cgi-bin/menu/delete"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse, common.BodyMap](ctx, sdk.client, uri, nil)
+	return err
 }
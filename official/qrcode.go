@@ -3,6 +3,8 @@ package official
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/url"
 
 	"github.com/medreams/wechat/common"
 )
@@ -32,28 +34,30 @@ func (sdk *SDK) GenerateQRCode(ctx context.Context, expireSeconds int, actionNam
 		})
 	})
 
-	req := &QrCodeRsp{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/qrcode/create?access_token=%s", sdk.AccessToken)
+	uri := "/cgi-bin/qrcode/create"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
+	return common.Call[QrCodeRsp](ctx, sdk.client, uri, bodyMap)
+}
 
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
+// 通过 ticket 换取二维码
+func (sdk *SDK) TicketGetQRCode(ctx context.Context, ticket string) ([]byte, error) {
+	stream, err := sdk.TicketGetQRCodeStream(ctx, ticket)
+	if err != nil {
+		return nil, err
 	}
+	defer stream.Close()
 
-	return req, nil
+	return io.ReadAll(stream)
 }
 
-// 通过 ticket 换取二维码
-func (sdk *SDK) TicketGetQRCode(ctx context.Context, ticket string) ([]byte, error) {
-	uri := fmt.Sprintf("https://mp.weixin.qq.com/cgi-bin/showqrcode?ticket=%s", ticket)
+// 通过 ticket 换取二维码，以流的方式返回图片，读取后需要 Close
+func (sdk *SDK) TicketGetQRCodeStream(ctx context.Context, ticket string) (*common.Stream, error) {
+	uri := fmt.Sprintf("https://mp.weixin.qq.com/cgi-bin/showqrcode?ticket=%s", url.QueryEscape(ticket))
 
-	bs, err := common.DoRequestGetByte(ctx, uri)
+	stream, err := sdk.client.WithoutToken().DoRequestGetStream(ctx, uri)
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
 
-	return bs, nil
+	return stream, nil
 }
@@ -81,6 +81,10 @@ type ReceivingEventMsg struct {
 	Latitude         string                `xml:"Latitude,omitempty"`         //地理位置纬度
 	Longitude        string                `xml:"Longitude,omitempty"`        //地理位置经度
 	Precision        string                `xml:"Precision,omitempty"`        //地理位置精度
+	//认证事件
+	ExpiredTime int64  `xml:"ExpiredTime,omitempty"` //有效期 (整形)，指的是时间戳，将于该时间戳认证过期
+	FailTime    int64  `xml:"FailTime,omitempty"`    //失败发生时间 (整形)，时间戳
+	FailReason  string `xml:"FailReason,omitempty"`  //认证失败的原因
 	//发布事件
 	PublishEventInfo ReceivingPublishMsg `xml:"PublishEventInfo"`
 	//扫顾问二维码后的事件推送
@@ -301,7 +305,7 @@ func (rm *ReceivingMessage) GetLocationMsgContent() (*ReceivingLocationMsg, erro
 // 获取链接信息内容
 func (rm *ReceivingMessage) GetLinkMsgContent() (*ReceivingLinkMsg, error) {
 
-	if rm.GetMsgType() == "location" {
+	if rm.GetMsgType() == "link" {
 		return &ReceivingLinkMsg{
 			Title:       rm.Title,
 			Description: rm.Description,
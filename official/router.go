@@ -0,0 +1,281 @@
+package official
+
+import (
+	"context"
+	"strings"
+)
+
+// 消息类型 MsgType
+const (
+	MsgTypeText       = "text"
+	MsgTypeImage      = "image"
+	MsgTypeVoice      = "voice"
+	MsgTypeVideo      = "video"
+	MsgTypeShortVideo = "shortvideo"
+	MsgTypeLocation   = "location"
+	MsgTypeLink       = "link"
+	MsgTypeEvent      = "event"
+)
+
+// 事件类型 Event
+const (
+	EventSubscribe                  = "subscribe"
+	EventUnsubscribe                = "unsubscribe"
+	EventScan                       = "SCAN"
+	EventLocation                   = "LOCATION"
+	EventClick                      = "CLICK"
+	EventView                       = "VIEW"
+	EventScanCodePush               = "scancode_push"
+	EventScanCodeWaitMsg            = "scancode_waitmsg"
+	EventPublishJobFinish           = "PUBLISHJOBFINISH"
+	EventGuideQrcodeScan            = "guide_qrcode_scan_event"
+	EventQualificationVerifySuccess = "qualification_verify_success"
+	EventQualificationVerifyFail    = "qualification_verify_fail"
+	EventNamingVerifySuccess        = "naming_verify_success"
+	EventNamingVerifyFail           = "naming_verify_fail"
+	EventAnnualRenew                = "annual_renew"
+	EventVerifyExpired              = "verify_expired"
+)
+
+// 扫描带参数二维码关注时 EventKey 的前缀
+const qrScenePrefix = "qrscene_"
+
+// SubscribeEvent 关注事件，扫描带参数二维码关注时 SceneKey、Ticket 不为空
+type SubscribeEvent struct {
+	SceneKey string // 二维码的参数值，已去掉 qrscene_ 前缀
+	Ticket   string // 二维码的ticket，可用来换取二维码图片
+}
+
+// ScanEvent 已关注用户扫描带参数二维码事件
+type ScanEvent struct {
+	SceneKey string // 二维码的参数值
+	Ticket   string // 二维码的ticket，可用来换取二维码图片
+}
+
+// MenuEvent 点击菜单拉取消息、跳转链接事件
+type MenuEvent struct {
+	EventKey string // CLICK 时为菜单 KEY 值，VIEW 时为跳转的 URL
+	MenuId   string // 个性化菜单的规则ID
+}
+
+// VerifyEvent 资质认证、名称认证、年审、认证过期事件
+type VerifyEvent struct {
+	Event       string // 事件类型，如 qualification_verify_success
+	ExpiredTime int64  // 认证成功、年审通知、认证过期时，认证过期的时间戳
+	FailTime    int64  // 认证失败时，失败发生的时间戳
+	FailReason  string // 认证失败的原因
+}
+
+// Middleware 消息处理中间件，调用 next 继续处理，可用于日志、鉴权、去重等
+type Middleware func(next MessageHandler) MessageHandler
+
+// Router 按消息类型、事件类型分发消息，实现 MessageHandler
+// 事件依次匹配 Event+EventKey、Event、MsgType event，都没有注册时使用默认处理函数
+type Router struct {
+	handlers       map[string]MessageHandler
+	middlewares    []Middleware
+	defaultHandler MessageHandler
+}
+
+func NewRouter() *Router {
+	return &Router{
+		handlers: make(map[string]MessageHandler),
+	}
+}
+
+// Use 添加中间件，第一个中间件最先执行
+func (r *Router) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// Default 没有匹配的处理函数时使用，未设置时回复 success
+func (r *Router) Default(h MessageHandler) {
+	r.defaultHandler = h
+}
+
+// Handle 按消息类型 MsgType 注册处理函数
+func (r *Router) Handle(msgType string, h MessageHandler) {
+	r.handlers[routeKey(msgType, "", "")] = h
+}
+
+// HandleEvent 按事件类型 Event 注册处理函数
+func (r *Router) HandleEvent(event string, h MessageHandler) {
+	r.handlers[routeKey(MsgTypeEvent, event, "")] = h
+}
+
+// HandleEventKey 按事件类型和 EventKey 注册处理函数，如指定 KEY 的菜单点击事件
+func (r *Router) HandleEventKey(event, eventKey string, h MessageHandler) {
+	r.handlers[routeKey(MsgTypeEvent, event, eventKey)] = h
+}
+
+// ServeMessage 经过中间件后分发消息
+func (r *Router) ServeMessage(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+	var h MessageHandler = MessageHandlerFunc(r.dispatch)
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		h = r.middlewares[i](h)
+	}
+	return h.ServeMessage(ctx, msg)
+}
+
+func (r *Router) dispatch(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+	keys := []string{routeKey(msg.MsgType, "", "")}
+	if msg.MsgType == MsgTypeEvent {
+		keys = []string{
+			routeKey(msg.MsgType, msg.Event, msg.EventKey),
+			routeKey(msg.MsgType, msg.Event, ""),
+			keys[0],
+		}
+	}
+	for _, key := range keys {
+		if h, ok := r.handlers[key]; ok {
+			return h.ServeMessage(ctx, msg)
+		}
+	}
+	if r.defaultHandler != nil {
+		return r.defaultHandler.ServeMessage(ctx, msg)
+	}
+	return nil, nil
+}
+
+// 事件类型大小写不统一，如 subscribe、SCAN，统一转为小写
+func routeKey(msgType, event, eventKey string) string {
+	key := strings.ToLower(msgType)
+	if event != "" {
+		key += "/" + strings.ToLower(event)
+	}
+	if eventKey != "" {
+		key += "/" + eventKey
+	}
+	return key
+}
+
+// OnText 文本消息
+func (r *Router) OnText(f func(ctx context.Context, msg *ReceivingMessage, content string) (*ReplyMessage, error)) {
+	r.Handle(MsgTypeText, MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		return f(ctx, msg, msg.Content)
+	}))
+}
+
+// OnImage 图片消息
+func (r *Router) OnImage(f func(ctx context.Context, msg *ReceivingMessage, image *ReceivingImageMsg) (*ReplyMessage, error)) {
+	r.Handle(MsgTypeImage, MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		image, _ := msg.GetImageMsgContent()
+		return f(ctx, msg, image)
+	}))
+}
+
+// OnVoice 语音消息
+func (r *Router) OnVoice(f func(ctx context.Context, msg *ReceivingMessage, voice *ReceivingVoiceMsg) (*ReplyMessage, error)) {
+	r.Handle(MsgTypeVoice, MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		voice, _ := msg.GetVoiceMsgContent()
+		return f(ctx, msg, voice)
+	}))
+}
+
+// OnVideo 视频、小视频消息
+func (r *Router) OnVideo(f func(ctx context.Context, msg *ReceivingMessage, video *ReceivingVideoMsg) (*ReplyMessage, error)) {
+	h := MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		video, _ := msg.GetVideoMsgContent()
+		return f(ctx, msg, video)
+	})
+	r.Handle(MsgTypeVideo, h)
+	r.Handle(MsgTypeShortVideo, h)
+}
+
+// OnLocation 地理位置消息
+func (r *Router) OnLocation(f func(ctx context.Context, msg *ReceivingMessage, location *ReceivingLocationMsg) (*ReplyMessage, error)) {
+	r.Handle(MsgTypeLocation, MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		location, _ := msg.GetLocationMsgContent()
+		return f(ctx, msg, location)
+	}))
+}
+
+// OnLink 链接消息
+func (r *Router) OnLink(f func(ctx context.Context, msg *ReceivingMessage, link *ReceivingLinkMsg) (*ReplyMessage, error)) {
+	r.Handle(MsgTypeLink, MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		link, _ := msg.GetLinkMsgContent()
+		return f(ctx, msg, link)
+	}))
+}
+
+// OnSubscribe 关注事件，包括扫描带参数二维码关注
+func (r *Router) OnSubscribe(f func(ctx context.Context, msg *ReceivingMessage, event *SubscribeEvent) (*ReplyMessage, error)) {
+	r.HandleEvent(EventSubscribe, MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		return f(ctx, msg, &SubscribeEvent{
+			SceneKey: strings.TrimPrefix(msg.EventKey, qrScenePrefix),
+			Ticket:   msg.Ticket,
+		})
+	}))
+}
+
+// OnUnsubscribe 取消关注事件，回复的消息用户收不到
+func (r *Router) OnUnsubscribe(f func(ctx context.Context, msg *ReceivingMessage) error) {
+	r.HandleEvent(EventUnsubscribe, MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		return nil, f(ctx, msg)
+	}))
+}
+
+// OnScan 已关注用户扫描带参数二维码事件
+func (r *Router) OnScan(f func(ctx context.Context, msg *ReceivingMessage, event *ScanEvent) (*ReplyMessage, error)) {
+	r.HandleEvent(EventScan, MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		return f(ctx, msg, &ScanEvent{SceneKey: msg.EventKey, Ticket: msg.Ticket})
+	}))
+}
+
+// OnClick 点击菜单拉取消息事件，eventKey 为空时处理所有菜单
+func (r *Router) OnClick(eventKey string, f func(ctx context.Context, msg *ReceivingMessage, event *MenuEvent) (*ReplyMessage, error)) {
+	r.HandleEventKey(EventClick, eventKey, menuHandler(f))
+}
+
+// OnView 点击菜单跳转链接事件
+func (r *Router) OnView(f func(ctx context.Context, msg *ReceivingMessage, event *MenuEvent) (*ReplyMessage, error)) {
+	r.HandleEvent(EventView, menuHandler(f))
+}
+
+func menuHandler(f func(ctx context.Context, msg *ReceivingMessage, event *MenuEvent) (*ReplyMessage, error)) MessageHandler {
+	return MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		return f(ctx, msg, &MenuEvent{EventKey: msg.EventKey, MenuId: msg.MenuId})
+	})
+}
+
+// OnScanCodePush 扫码推事件，包括弹出“消息接收中”提示框的 scancode_waitmsg
+func (r *Router) OnScanCodePush(f func(ctx context.Context, msg *ReceivingMessage, info *EventScanCodeInfo) (*ReplyMessage, error)) {
+	h := MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		return f(ctx, msg, &msg.ScanCodeInfo)
+	})
+	r.HandleEvent(EventScanCodePush, h)
+	r.HandleEvent(EventScanCodeWaitMsg, h)
+}
+
+// OnPublishJobFinish 发布结果事件
+func (r *Router) OnPublishJobFinish(f func(ctx context.Context, msg *ReceivingMessage, info *ReceivingPublishMsg) (*ReplyMessage, error)) {
+	r.HandleEvent(EventPublishJobFinish, MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		return f(ctx, msg, &msg.PublishEventInfo)
+	}))
+}
+
+// OnGuideQrcodeScan 扫顾问二维码事件
+func (r *Router) OnGuideQrcodeScan(f func(ctx context.Context, msg *ReceivingMessage, info *ReceivingGuideScanMsg) (*ReplyMessage, error)) {
+	r.HandleEvent(EventGuideQrcodeScan, MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		return f(ctx, msg, &msg.GuideScanEvent)
+	}))
+}
+
+// OnVerify 资质认证、名称认证成功或失败，年审通知，认证过期事件
+func (r *Router) OnVerify(f func(ctx context.Context, msg *ReceivingMessage, event *VerifyEvent) (*ReplyMessage, error)) {
+	h := MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		return f(ctx, msg, &VerifyEvent{
+			Event:       msg.Event,
+			ExpiredTime: msg.ExpiredTime,
+			FailTime:    msg.FailTime,
+			FailReason:  msg.FailReason,
+		})
+	})
+	for _, event := range []string{
+		EventQualificationVerifySuccess, EventQualificationVerifyFail,
+		EventNamingVerifySuccess, EventNamingVerifyFail,
+		EventAnnualRenew, EventVerifyExpired,
+	} {
+		r.HandleEvent(event, h)
+	}
+}
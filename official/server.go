@@ -0,0 +1,275 @@
+package official
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultReplyTimeout 微信服务器5秒内收不到响应会断开连接并重试，留出网络传输的时间
+	DefaultReplyTimeout = 4500 * time.Millisecond
+	// DefaultTimestampSkew 请求中的 timestamp 与本机时间相差超过该值时拒绝，防止重放
+	DefaultTimestampSkew = 5 * time.Minute
+
+	// 推送的消息最大 1MB
+	maxMessageSize = 1 << 20
+)
+
+// MessageHandler 处理公众号推送的消息和事件，返回 nil 时回复 success
+type MessageHandler interface {
+	ServeMessage(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error)
+}
+
+// MessageHandlerFunc 函数形式的 MessageHandler
+type MessageHandlerFunc func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error)
+
+func (f MessageHandlerFunc) ServeMessage(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+	return f(ctx, msg)
+}
+
+// ServerOption 消息服务器配置
+type ServerOption func(s *Server)
+
+// WithReplyTimeout 等待 handler 回复的时间，超时后回复 success，默认 DefaultReplyTimeout
+func WithReplyTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.timeout = timeout
+	}
+}
+
+// WithTimestampSkew 允许的 timestamp 误差，为0时不校验，默认 DefaultTimestampSkew
+func WithTimestampSkew(skew time.Duration) ServerOption {
+	return func(s *Server) {
+		s.skew = skew
+	}
+}
+
+// WithServerLogger handler 返回错误、超时时记录日志，默认使用 slog.Default()
+func WithServerLogger(logger *slog.Logger) ServerOption {
+	return func(s *Server) {
+		if logger != nil {
+			s.logger = logger
+		}
+	}
+}
+
+// WithMessageCrypto 兼容模式、安全模式下解密推送的消息并加密回复
+func WithMessageCrypto(crypto *MessageCrypto) ServerOption {
+	return func(s *Server) {
+		s.crypto = crypto
+	}
+}
+
+// WithDedup 使用 store 对微信重试推送的消息去重，store 为 nil 时使用 NewMemoryDedupStore，见 Dedup
+func WithDedup(store DedupStore) ServerOption {
+	return func(s *Server) {
+		s.handler = Dedup(store, 0)(s.handler)
+	}
+}
+
+// WithAsyncReply handler 在 a 的 worker 中执行，超过回复时间时先回复 success，
+// handler 继续在后台执行，返回的回复通过客服消息发送；a 需由调用方 Close
+func WithAsyncReply(a *AsyncReplier) ServerOption {
+	return func(s *Server) {
+		s.async = a
+	}
+}
+
+// Server 公众号服务器配置中的 URL，实现 http.Handler
+// GET 请求校验签名后返回 echostr，POST 请求解析消息后交给 handler 处理并回复
+type Server struct {
+	token   string
+	handler MessageHandler
+	timeout time.Duration
+	skew    time.Duration
+	logger  *slog.Logger
+	crypto  *MessageCrypto
+	async   *AsyncReplier
+}
+
+// NewServer token 为服务器配置中的令牌(Token)
+func NewServer(token string, handler MessageHandler, opts ...ServerOption) *Server {
+	s := &Server{
+		token:   token,
+		handler: handler,
+		timeout: DefaultReplyTimeout,
+		skew:    DefaultTimestampSkew,
+		logger:  slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// NewServer 使用 SetMessageConfig 设置的令牌创建消息服务器，设置了 EncodingAESKey 时支持兼容模式、安全模式
+func (sdk *SDK) NewServer(handler MessageHandler, opts ...ServerOption) *Server {
+	if sdk.EncodingAESKey != "" {
+		opts = append([]ServerOption{WithMessageCrypto(NewMessageCrypto(sdk.Appid, sdk.MsgToken, sdk.EncodingAESKey))}, opts...)
+	}
+	return NewServer(sdk.MsgToken, handler, opts...)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if err := s.verify(query); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		// 配置服务器地址时的校验
+		io.WriteString(w, query.Get("echostr"))
+	case http.MethodPost:
+		s.serveMessage(w, r)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// 校验 signature 和 timestamp
+func (s *Server) verify(query url.Values) error {
+	timestamp := query.Get("timestamp")
+	if !CheckSignature(s.token, query.Get("signature"), timestamp, query.Get("nonce")) {
+		return errors.New("invalid signature")
+	}
+	if s.skew <= 0 {
+		return nil
+	}
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %s", timestamp)
+	}
+	if d := time.Since(time.Unix(sec, 0)); d > s.skew || d < -s.skew {
+		return fmt.Errorf("timestamp %s expired", timestamp)
+	}
+	return nil
+}
+
+func (s *Server) serveMessage(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 兼容模式、安全模式下消息体为密文
+	query := r.URL.Query()
+	encrypted := query.Get("encrypt_type") == "aes"
+	if encrypted {
+		if s.crypto == nil {
+			http.Error(w, "EncodingAESKey is not configured", http.StatusBadRequest)
+			return
+		}
+		if body, err = s.crypto.Decrypt(query.Get("msg_signature"), query.Get("timestamp"), query.Get("nonce"), body); err != nil {
+			s.logger.Warn("wechat message decrypt failed", "error", err)
+			http.Error(w, "invalid message", http.StatusBadRequest)
+			return
+		}
+	}
+
+	msg, err := NewReceivingMessage().Unmarshal(body)
+	if err != nil {
+		http.Error(w, "invalid message", http.StatusBadRequest)
+		return
+	}
+
+	reply, err := s.handle(r.Context(), msg)
+	if err != nil {
+		// 返回错误时微信会提示“该公众号暂时无法提供服务”，记录日志后回复 success
+		s.logger.Error("wechat message handler failed", "msgtype", msg.MsgType, "event", msg.Event, "openid", msg.FromUserName, "error", err)
+	}
+	s.writeReply(w, msg, reply, encrypted)
+}
+
+type handleResult struct {
+	reply *ReplyMessage
+	err   error
+}
+
+// 在 timeout 内等待 handler 返回，超时后取消 ctx
+func (s *Server) handle(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+	if s.async != nil {
+		t := newAsyncTask(ctx, s.handler, msg)
+		if s.async.submit(t) {
+			return s.wait(t)
+		}
+		// 队列已满、已关闭时丢弃消息并回复 success，避免请求堆积时不受 worker 数量限制
+		s.logger.Warn("wechat async reply queue full, message dropped", "msgtype", msg.MsgType, "event", msg.Event, "openid", msg.FromUserName)
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	done := make(chan handleResult, 1)
+	go func() {
+		done <- serveMessage(ctx, s.handler, msg)
+	}()
+
+	select {
+	case res := <-done:
+		return res.reply, res.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("reply timeout after %s: %w", s.timeout, ctx.Err())
+	}
+}
+
+// 在 timeout 内等待后台 handler 返回，超时后回复 success，由 AsyncReplier 发送客服消息
+func (s *Server) wait(t *asyncTask) (*ReplyMessage, error) {
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-t.done:
+		return res.reply, res.err
+	case <-timer.C:
+		if res, ok := t.abandon(); ok {
+			return res.reply, res.err
+		}
+		s.logger.Info("wechat message reply deferred to customer message", "msgtype", t.msg.MsgType, "event", t.msg.Event, "openid", t.msg.FromUserName)
+		return nil, nil
+	}
+}
+
+// 回复消息，reply 为 nil 时回复 success，encrypted 为 true 时加密回复
+func (s *Server) writeReply(w http.ResponseWriter, msg *ReceivingMessage, reply *ReplyMessage, encrypted bool) {
+	if reply == nil || reply.MsgType == "" {
+		io.WriteString(w, "success")
+		return
+	}
+
+	if reply.ToUserName == "" {
+		reply.ToUserName = CDATA(msg.FromUserName)
+	}
+	if reply.FromUserName == "" {
+		reply.FromUserName = CDATA(msg.ToUserName)
+	}
+	if reply.CreateTime == 0 {
+		reply.CreateTime = time.Now().Unix()
+	}
+
+	var bs []byte
+	if encrypted {
+		var err error
+		if bs, err = reply.MarshalEncrypted(s.crypto); err != nil {
+			s.logger.Error("wechat reply encrypt failed", "error", err)
+		}
+	} else {
+		bs = reply.Marshal()
+	}
+	if bs == nil {
+		io.WriteString(w, "success")
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write(bs)
+}
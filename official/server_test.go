@@ -0,0 +1,348 @@
+package official
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/medreams/wechat/pkg/util"
+)
+
+const testToken = "TOKEN"
+
+func signedURL(query url.Values) string {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	params := []string{testToken, timestamp, "nonce"}
+	sort.Strings(params)
+	query.Set("timestamp", timestamp)
+	query.Set("nonce", "nonce")
+	query.Set("signature", fmt.Sprintf("%x", sha1.Sum([]byte(strings.Join(params, "")))))
+	return "/wechat?" + query.Encode()
+}
+
+func TestServer(t *testing.T) {
+	srv := NewServer(testToken, MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		switch msg.Content {
+		case "slow":
+			<-ctx.Done()
+			return nil, ctx.Err()
+		case "hello":
+			reply := NewReplyMessage()
+			reply.SetContent("world")
+			return reply, nil
+		}
+		return nil, nil
+	}), WithReplyTimeout(50*time.Millisecond))
+
+	serve := func(method, target, body string) (int, string) {
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		bs, _ := io.ReadAll(w.Result().Body)
+		return w.Code, string(bs)
+	}
+
+	if code, body := serve(http.MethodGet, signedURL(url.Values{"echostr": {"ECHO"}}), ""); code != http.StatusOK || body != "ECHO" {
+		t.Fatalf("echostr: %d %s", code, body)
+	}
+	if code, _ := serve(http.MethodGet, "/wechat?echostr=ECHO&signature=bad&timestamp=1&nonce=n", ""); code != http.StatusForbidden {
+		t.Fatalf("bad signature: %d", code)
+	}
+
+	msg := `<xml><ToUserName><![CDATA[gh_1]]></ToUserName><FromUserName><![CDATA[openid]]></FromUserName><CreateTime>1700000000</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[%s]]></Content><MsgId>1</MsgId></xml>`
+	code, body := serve(http.MethodPost, signedURL(url.Values{}), fmt.Sprintf(msg, "hello"))
+	if code != http.StatusOK || !strings.Contains(body, "<ToUserName><![CDATA[openid]]></ToUserName>") || !strings.Contains(body, "<Content><![CDATA[world]]></Content>") {
+		t.Fatalf("reply: %d %s", code, body)
+	}
+	if _, body := serve(http.MethodPost, signedURL(url.Values{}), fmt.Sprintf(msg, "other")); body != "success" {
+		t.Fatalf("no reply: %s", body)
+	}
+	if _, body := serve(http.MethodPost, signedURL(url.Values{}), fmt.Sprintf(msg, "slow")); body != "success" {
+		t.Fatalf("timeout: %s", body)
+	}
+}
+
+func TestServerEncrypted(t *testing.T) {
+	const (
+		appid = "wx_appid"
+		key   = "abcdefghijklmnopqrstuvwxyz0123456789ABCDEFG"
+	)
+	sdk := New(appid, "secret", "")
+	sdk.SetMessageConfig(testToken, key)
+	srv := sdk.NewServer(MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		reply := NewReplyMessage()
+		reply.SetContent(CDATA("echo " + msg.Content))
+		return reply, nil
+	}))
+
+	plaintext := `<xml><ToUserName><![CDATA[gh_1]]></ToUserName><FromUserName><![CDATA[openid]]></FromUserName><CreateTime>1700000000</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[hello]]></Content><MsgId>1</MsgId></xml>`
+	encrypt, err := util.EncryptMsg([]byte(util.RandomString(16)), []byte(plaintext), appid, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := signedURL(url.Values{"encrypt_type": {"aes"}})
+	query, _ := url.ParseQuery(strings.SplitN(target, "?", 2)[1])
+	query.Set("msg_signature", sign(testToken, query.Get("timestamp"), query.Get("nonce"), string(encrypt)))
+	body := fmt.Sprintf(`<xml><ToUserName><![CDATA[gh_1]]></ToUserName><Encrypt><![CDATA[%s]]></Encrypt></xml>`, encrypt)
+
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/wechat?"+query.Encode(), strings.NewReader(body)))
+	reply := &EncryptedReply{}
+	if err := xml.Unmarshal(w.Body.Bytes(), reply); err != nil {
+		t.Fatalf("%v: %s", err, w.Body.String())
+	}
+	if !CheckMsgSignature(testToken, string(reply.MsgSignature), strconv.FormatInt(reply.TimeStamp, 10), string(reply.Nonce), string(reply.Encrypt)) {
+		t.Fatal("invalid reply signature")
+	}
+	_, bs, err := util.DecryptMsg(appid, string(reply.Encrypt), key)
+	if err != nil || !strings.Contains(string(bs), "<Content><![CDATA[echo hello]]></Content>") {
+		t.Fatalf("DecryptMsg() = %s, %v", bs, err)
+	}
+
+	// msg_signature 错误
+	query.Set("msg_signature", "bad")
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/wechat?"+query.Encode(), strings.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("bad msg_signature: %d", w.Code)
+	}
+}
+
+func TestRouter(t *testing.T) {
+	var trace []string
+	r := NewRouter()
+	r.Use(func(next MessageHandler) MessageHandler {
+		return MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+			trace = append(trace, "middleware")
+			return next.ServeMessage(ctx, msg)
+		})
+	})
+	r.OnSubscribe(func(ctx context.Context, msg *ReceivingMessage, event *SubscribeEvent) (*ReplyMessage, error) {
+		trace = append(trace, "subscribe:"+event.SceneKey)
+		return nil, nil
+	})
+	r.OnScan(func(ctx context.Context, msg *ReceivingMessage, event *ScanEvent) (*ReplyMessage, error) {
+		trace = append(trace, "scan:"+event.SceneKey)
+		return nil, nil
+	})
+	r.OnClick("", func(ctx context.Context, msg *ReceivingMessage, event *MenuEvent) (*ReplyMessage, error) {
+		trace = append(trace, "click")
+		return nil, nil
+	})
+	r.OnClick("V1001_GOOD", func(ctx context.Context, msg *ReceivingMessage, event *MenuEvent) (*ReplyMessage, error) {
+		trace = append(trace, "click:"+event.EventKey)
+		return nil, nil
+	})
+	r.OnVerify(func(ctx context.Context, msg *ReceivingMessage, event *VerifyEvent) (*ReplyMessage, error) {
+		trace = append(trace, event.Event+":"+event.FailReason)
+		return nil, nil
+	})
+	r.Default(MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		trace = append(trace, "default:"+msg.MsgType)
+		return nil, nil
+	}))
+
+	for _, body := range []string{
+		`<xml><MsgType>event</MsgType><Event>subscribe</Event><EventKey>qrscene_123</EventKey><Ticket>T</Ticket></xml>`,
+		`<xml><MsgType>event</MsgType><Event>SCAN</Event><EventKey>123</EventKey></xml>`,
+		`<xml><MsgType>event</MsgType><Event>CLICK</Event><EventKey>V1001_GOOD</EventKey></xml>`,
+		`<xml><MsgType>event</MsgType><Event>CLICK</Event><EventKey>OTHER</EventKey></xml>`,
+		`<xml><MsgType>event</MsgType><Event>qualification_verify_fail</Event><FailTime>1</FailTime><FailReason>reason</FailReason></xml>`,
+		`<xml><MsgType>text</MsgType><Content>hi</Content></xml>`,
+	} {
+		msg, err := NewReceivingMessage().Unmarshal([]byte(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.ServeMessage(context.Background(), msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := "middleware subscribe:123 middleware scan:123 middleware click:V1001_GOOD middleware click middleware qualification_verify_fail:reason middleware default:text"
+	if got := strings.Join(trace, " "); got != want {
+		t.Fatalf("trace = %s", got)
+	}
+}
+
+func TestServerDedup(t *testing.T) {
+	var calls int
+	srv := NewServer(testToken, MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		calls++
+		return nil, nil
+	}), WithDedup(NewMemoryDedupStore()))
+
+	for _, body := range []string{
+		`<xml><ToUserName>gh_dedup</ToUserName><FromUserName>openid</FromUserName><CreateTime>1700000000</CreateTime><MsgType>text</MsgType><Content>hi</Content><MsgId>42</MsgId></xml>`,
+		`<xml><ToUserName>gh_dedup</ToUserName><FromUserName>openid</FromUserName><CreateTime>1700000000</CreateTime><MsgType>event</MsgType><Event>subscribe</Event></xml>`,
+	} {
+		// 微信重试3次
+		for i := 0; i < 3; i++ {
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, httptest.NewRequest(http.MethodPost, signedURL(url.Values{}), strings.NewReader(body)))
+			if w.Body.String() != "success" {
+				t.Fatalf("reply: %s", w.Body.String())
+			}
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2", calls)
+	}
+}
+
+func TestDedupRelease(t *testing.T) {
+	store := NewMemoryDedupStore()
+	var calls int
+	handler := Dedup(store, 0)(MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		calls++
+		switch calls {
+		case 1:
+			return nil, errors.New("failed")
+		case 2:
+			panic("boom")
+		}
+		return nil, nil
+	}))
+	msg := &ReceivingMessage{ToUserName: "gh_dedup", MsgId: "43"}
+
+	// 处理失败、panic 后微信重试推送时重新处理
+	if _, err := handler.ServeMessage(context.Background(), msg); err == nil {
+		t.Fatal("want error")
+	}
+	func() {
+		defer func() { recover() }()
+		handler.ServeMessage(context.Background(), msg)
+	}()
+	for i := 0; i < 2; i++ {
+		if _, err := handler.ServeMessage(context.Background(), msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 3 {
+		t.Fatalf("calls = %d, want 3", calls)
+	}
+}
+
+func TestMemoryDedupStoreEviction(t *testing.T) {
+	store := NewMemoryDedupStore()
+	ctx := context.Background()
+	for i := 0; i < 100; i++ {
+		store.Claim(ctx, strconv.Itoa(i), time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if first, _ := store.Claim(ctx, "0", time.Millisecond); !first {
+		t.Fatal("expired key not claimable")
+	}
+	if n := len(store.expires); n != 1 {
+		t.Fatalf("%d keys kept, want 1", n)
+	}
+}
+
+func TestServerAsyncReply(t *testing.T) {
+	type sent struct {
+		openid string
+		body   string
+	}
+	sentCh := make(chan sent, 1)
+	replier := NewAsyncReplier(func(ctx context.Context, openid string, msg *ReplyMessage) error {
+		bodyMap, err := customerMessageBody(openid, msg)
+		if err != nil {
+			return err
+		}
+		sentCh <- sent{openid: openid, body: bodyMap.JsonBody()}
+		return nil
+	}, AsyncConfig{Workers: 1, QueueSize: 1})
+
+	srv := NewServer(testToken, MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		if msg.Content == "slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		reply := NewReplyMessage()
+		reply.MsgType = "text"
+		content := CDATA("re:" + msg.Content)
+		reply.Content = &content
+		return reply, nil
+	}), WithReplyTimeout(20*time.Millisecond), WithAsyncReply(replier))
+
+	const msg = `<xml><ToUserName>gh_async</ToUserName><FromUserName>openid</FromUserName><CreateTime>1700000000</CreateTime><MsgType>text</MsgType><Content>%s</Content></xml>`
+	serve := func(content string) string {
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, httptest.NewRequest(http.MethodPost, signedURL(url.Values{}), strings.NewReader(fmt.Sprintf(msg, content))))
+		return w.Body.String()
+	}
+
+	if body := serve("fast"); !strings.Contains(body, "re:fast") {
+		t.Fatalf("fast reply: %s", body)
+	}
+	if body := serve("slow"); body != "success" {
+		t.Fatalf("slow reply: %s", body)
+	}
+
+	select {
+	case s := <-sentCh:
+		want := `{"msgtype":"text","text":{"content":"re:slow"},"touser":"openid"}`
+		if s.openid != "openid" || s.body != want {
+			t.Fatalf("sent %s: %s", s.openid, s.body)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("customer message not sent")
+	}
+
+	if err := replier.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// 关闭后丢弃消息并回复 success
+	if body := serve("fast"); body != "success" {
+		t.Fatalf("reply after close: %s", body)
+	}
+}
+
+func TestAsyncReplierCloseCancelSend(t *testing.T) {
+	sending := make(chan struct{})
+	sendErr := make(chan error, 1)
+	replier := NewAsyncReplier(func(ctx context.Context, openid string, msg *ReplyMessage) error {
+		close(sending)
+		<-ctx.Done()
+		sendErr <- ctx.Err()
+		return ctx.Err()
+	}, AsyncConfig{Workers: 1})
+
+	srv := NewServer(testToken, MessageHandlerFunc(func(ctx context.Context, msg *ReceivingMessage) (*ReplyMessage, error) {
+		time.Sleep(50 * time.Millisecond)
+		reply := NewReplyMessage()
+		reply.MsgType = "text"
+		return reply, nil
+	}), WithReplyTimeout(10*time.Millisecond), WithAsyncReply(replier))
+
+	const msg = `<xml><ToUserName>gh_async</ToUserName><FromUserName>openid</FromUserName><CreateTime>1700000000</CreateTime><MsgType>text</MsgType><Content>slow</Content></xml>`
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodPost, signedURL(url.Values{}), strings.NewReader(msg)))
+	if body := w.Body.String(); body != "success" {
+		t.Fatalf("reply: %s", body)
+	}
+	<-sending
+
+	// Close 超时后取消发送，不必等待 asyncSendTimeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := replier.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Close() = %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Close() took %s", d)
+	}
+	if err := <-sendErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("send ctx err = %v", err)
+	}
+}
@@ -10,11 +10,16 @@ import (
 // 验证消息的确来自微信服务器()
 // 微信加密签名，signature结合了开发者填写的 token 参数和请求中的 timestamp 参数、nonce参数。
 func CheckSignature(token, signature, timestamp, nonce string) bool {
-	params := []string{
-		token,
-		timestamp,
-		nonce,
-	}
+	return sign(token, timestamp, nonce) == signature
+}
+
+// 安全模式下验证消息签名，msg_signature 结合了 token、timestamp、nonce 和消息密文 Encrypt
+func CheckMsgSignature(token, msgSignature, timestamp, nonce, encrypt string) bool {
+	return sign(token, timestamp, nonce, encrypt) == msgSignature
+}
+
+// 参数按字典序排序后拼接计算 sha1
+func sign(params ...string) string {
 	sort.Strings(params)
 
 	h := sha1.New()
@@ -22,6 +27,5 @@ func CheckSignature(token, signature, timestamp, nonce string) bool {
 		_, _ = io.WriteString(h, s)
 	}
 
-	tempStr := fmt.Sprintf("%x", h.Sum(nil))
-	return tempStr == signature
+	return fmt.Sprintf("%x", h.Sum(nil))
 }
@@ -2,7 +2,6 @@ package official
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/medreams/wechat/common"
 )
@@ -25,18 +24,9 @@ func (sdk *SDK) CreateUserTag(ctx context.Context, tagName string) (*Tag, error)
 		b.Set("name", tagName)
 	})
 
-	req := &Tag{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/tags/create?access_token=%s", sdk.AccessToken)
+	uri := "/cgi-bin/tags/create"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request create tag: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return req, nil
+	return common.Call[Tag](ctx, sdk.client, uri, bodyMap)
 }
 
 type Tags struct {
@@ -46,18 +36,9 @@ type Tags struct {
 
 // 获取公众号已创建的标签
 func (sdk *SDK) GetUserTagList(ctx context.Context) (*Tags, error) {
-	req := &Tags{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/tags/get?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestGet(ctx, uri, req); err != nil {
-		return nil, fmt.Errorf("do request get access_token: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/cgi-bin/tags/get"
 
-	return req, nil
+	return common.CallGet[Tags](ctx, sdk.client, uri)
 }
 
 // 编辑标签
@@ -68,18 +49,10 @@ func (sdk *SDK) UpdateUserTag(ctx context.Context, tagId int, tagName string) er
 		b.Set("name", tagName)
 	})
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/tags/update?access_token=%s", sdk.AccessToken)
+	uri := "/cgi-bin/tags/update"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request get tags: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 删除标签
@@ -89,18 +62,10 @@ func (sdk *SDK) DeleteUserTag(ctx context.Context, tagId int) error {
 		b.Set("id", tagId)
 	})
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/tags/delete?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request delete tags: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/cgi-bin/tags/delete"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 获取标签下粉丝列表
@@ -109,18 +74,9 @@ func (sdk *SDK) GetUserTagUserList(ctx context.Context, tagId int, nextOpenid st
 	bodyMap.Set("tagid", tagId)
 	bodyMap.Set("next_openid", nextOpenid)
 
-	req := &UserOpenidList{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/user/tag/get?access_token=%s", sdk.AccessToken)
+	uri := "/cgi-bin/user/tag/get"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request get tag userlist: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return req, nil
+	return common.Call[UserOpenidList](ctx, sdk.client, uri, bodyMap)
 }
 
 // 批量为用户打标签
@@ -129,18 +85,10 @@ func (sdk *SDK) SetUserTagBatch(ctx context.Context, tagId int, openids []string
 	bodyMap.Set("tagid", tagId)
 	bodyMap.Set("openid_list", openids)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/tags/members/batchtagging?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request batch set user tag: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/cgi-bin/tags/members/batchtagging"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 // 批量为用户取消标签
@@ -149,18 +97,10 @@ func (sdk *SDK) UnSetUserTagBatch(ctx context.Context, tagId int, openids []stri
 	bodyMap.Set("tagid", tagId)
 	bodyMap.Set("openid_list", openids)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/tags/members/batchuntagging?access_token=%s", sdk.AccessToken)
+	uri := "/cgi-bin/tags/members/batchuntagging"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request batch set user tag: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
 
 type TagIdListData struct {
@@ -173,16 +113,7 @@ func (sdk *SDK) GetUserUserTagIdList(ctx context.Context, openid string) (*TagId
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("openid", openid)
 
-	req := &TagIdListData{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/tags/getidlist?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request get user usertag id list: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/cgi-bin/tags/getidlist"
 
-	return req, nil
+	return common.Call[TagIdListData](ctx, sdk.client, uri, bodyMap)
 }
@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/medreams/wechat/common"
+	"github.com/medreams/wechat/pkg/util"
 )
 
 // WxMessageTemplate
@@ -48,18 +49,9 @@ type WxSendTemplateMessageParam struct {
 
 // GetTemplateList 获取私有模版
 func (sdk *SDK) GetMessageTemplateList(ctx context.Context, appid string) (*WxGetTemplateRes, error) {
-	req := &WxGetTemplateRes{}
+	uri := "/cgi-bin/template/get_all_private_template"
 
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/template/get_all_private_template?access_token=%s", sdk.AccessToken)
-	if err := common.DoRequestGet(ctx, uri, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return req, nil
+	return common.CallGet[WxGetTemplateRes](ctx, sdk.client, uri)
 }
 
 // SendTemplateMessage 发送模版信息
@@ -89,19 +81,18 @@ func (sdk *SDK) SendTemplateMessage(ctx context.Context, param *WxSendTemplateMe
 		bodyMap.Set("url", param.Url)
 	}
 
-	if param.ClientMsgId != "" {
-		bodyMap.Set("client_msg_id", param.ClientMsgId)
+	// 未指定防重入id时生成一个，网络错误重试时不会重复发送
+	clientMsgId := param.ClientMsgId
+	if clientMsgId == "" {
+		clientMsgId = util.RandomString(32)
 	}
+	bodyMap.Set("client_msg_id", clientMsgId)
 
-	req := &WxSendTemplateMessageRes{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/message/template/send?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return "", fmt.Errorf("do request: %w", err)
-	}
+	uri := "/cgi-bin/message/template/send"
 
-	if req.ErrCode != 0 {
-		return "", fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
+	req, err := common.Call[WxSendTemplateMessageRes](ctx, sdk.client, uri, bodyMap)
+	if err != nil {
+		return "", err
 	}
 
 	return strconv.FormatInt(req.Msgid, 10), nil
@@ -2,7 +2,6 @@ package official
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/medreams/wechat/common"
 )
@@ -33,14 +32,9 @@ type UserInfo struct {
 // 文档地址 https://developers.weixin.qq.com/doc/offiaccount/User_Management/Get_users_basic_information_UnionID.html#UinonId
 func (sdk *SDK) Openid2UserInfo(ctx context.Context, openid string) (user *UserInfo, err error) {
 
-	user = &UserInfo{}
-	url := "https://api.weixin.qq.com/cgi-bin/user/info?access_token=" + sdk.AccessToken + "&openid=" + openid + "&lang=zh_CN"
+	url := "/cgi-bin/user/info?openid=" + openid + "&lang=zh_CN"
 
-	if err = common.DoRequestGet(ctx, url, user); err != nil {
-		return nil, fmt.Errorf("do request get userinfo: %w", err)
-	}
-
-	return user, nil
+	return common.CallGet[UserInfo](ctx, sdk.client, url)
 }
 
 type UserList struct {
@@ -56,8 +50,7 @@ type BatchGetUserListParam struct {
 // 文档地址 https://developers.weixin.qq.com/doc/offiaccount/User_Management/Get_users_basic_information_UnionID.html#UinonId
 func (sdk *SDK) Openid2UserInfoBatch(ctx context.Context, openids []string, lang string) (*UserList, error) {
 
-	req := &UserList{}
-	url := "https://api.weixin.qq.com/cgi-bin/user/info/batchget?access_token=" + sdk.AccessToken
+	url := "/cgi-bin/user/info/batchget"
 
 	if lang == "" {
 		lang = "zh_CN"
@@ -74,15 +67,7 @@ func (sdk *SDK) Openid2UserInfoBatch(ctx context.Context, openids []string, lang
 	bodyMap := make(common.BodyMap)
 	bodyMap.Set("user_list", openidList)
 
-	if err := common.DoRequestPost(ctx, url, bodyMap, req); err != nil {
-		return nil, fmt.Errorf("do request get userinfo: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return req, nil
+	return common.Call[UserList](ctx, sdk.client, url, bodyMap)
 }
 
 type UserOpenidList struct {
@@ -99,19 +84,9 @@ type UserOpenidList struct {
 // 文档地址 https://developers.weixin.qq.com/doc/offiaccount/User_Management/Getting_a_User_List.html
 func (sdk *SDK) GetUserOpenidList(ctx context.Context, nextOpenid string) (*UserOpenidList, error) {
 
-	req := &UserOpenidList{}
+	url := "/cgi-bin/user/get?next_openid=" + nextOpenid
 
-	url := "https://api.weixin.qq.com/cgi-bin/user/get?access_token=" + sdk.AccessToken + "&next_openid=" + nextOpenid
-
-	if err := common.DoRequestGet(ctx, url, req); err != nil {
-		return nil, fmt.Errorf("do request get userlist: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return req, nil
+	return common.CallGet[UserOpenidList](ctx, sdk.client, url)
 }
 
 // 设置用户备注名 https://developers.weixin.qq.com/doc/offiaccount/User_Management/Configuring_user_notes.html
@@ -120,16 +95,8 @@ func (sdk *SDK) SetUserRemark(ctx context.Context, openid, remark string) error
 	bodyMap.Set("openid", openid)
 	bodyMap.Set("remark", remark)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/user/info/updateremark?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/cgi-bin/user/info/updateremark"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
@@ -21,15 +21,11 @@ type WxWebAccessToekn struct {
 
 // 网页授权和开放平台网页code获取access_token(此access_token,只能在网页授权和开放平台网页中使用)
 func (sdk *SDK) Code2WebAccessToken(ctx context.Context, code string) (req *WxWebAccessToekn, err error) {
-	req = &WxWebAccessToekn{}
-	URL := fmt.Sprintf("https://api.weixin.qq.com/sns/oauth2/access_token?appid=%s&secret=%s&code=%s&grant_type=authorization_code", sdk.Appid, sdk.Secret, code)
+	URL := fmt.Sprintf("/sns/oauth2/access_token?appid=%s&secret=%s&code=%s&grant_type=authorization_code", sdk.Appid, sdk.Secret, code)
 
-	if err = common.DoRequestGet(ctx, URL, req); err != nil {
-		return nil, fmt.Errorf("do request get access_token: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("get access_token error: %d, %s", req.ErrCode, req.ErrMsg)
+	req, err = common.CallGet[WxWebAccessToekn](ctx, sdk.client.WithoutToken(), URL)
+	if err != nil {
+		return nil, err
 	}
 
 	req.ExpiresTime = time.Now().Unix() + int64(req.ExpiresIn)
@@ -39,15 +35,11 @@ func (sdk *SDK) Code2WebAccessToken(ctx context.Context, code string) (req *WxWe
 }
 
 func (sdk *SDK) RefreshAccessToken(ctx context.Context, refreshToken string) (req *WxWebAccessToekn, err error) {
-	req = &WxWebAccessToekn{}
-	URL := fmt.Sprintf("https://api.weixin.qq.com/sns/oauth2/refresh_token?grant_type=refresh_token&appid=%s&refresh_token=%s", sdk.Appid, refreshToken)
-
-	if err = common.DoRequestGet(ctx, URL, req); err != nil {
-		return nil, fmt.Errorf("do request get access_token: %w", err)
-	}
+	URL := fmt.Sprintf("/sns/oauth2/refresh_token?grant_type=refresh_token&appid=%s&refresh_token=%s", sdk.Appid, refreshToken)
 
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("get access_token error: %d, %s", req.ErrCode, req.ErrMsg)
+	req, err = common.CallGet[WxWebAccessToekn](ctx, sdk.client.WithoutToken(), URL)
+	if err != nil {
+		return nil, err
 	}
 
 	req.ExpiresTime = time.Now().Unix() + int64(req.ExpiresIn)
@@ -1,10 +1,18 @@
 package util
 
+import "io"
+
 const (
 	NULL = ""
 )
 
+// File 上传的文件，Content、Path、Reader 三选一；Path、Reader 上传时流式读取，不会一次读入内存
 type File struct {
-	Name    string `json:"name"`
-	Content []byte `json:"content"`
+	Name    string    `json:"name"`
+	Content []byte    `json:"content"`
+	Path    string    `json:"-"` // 本地文件路径，每次上传（包括重试）重新打开
+	Reader  io.Reader `json:"-"` // 能 Seek 时每次上传从头读取，否则只能上传一次
+	Size    int64     `json:"-"` // Reader 的大小，未知时为 0
+
+	consumed bool
 }
@@ -0,0 +1,142 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrFileConsumed File.Reader 不能 Seek 且已经读取过，无法重新上传
+var ErrFileConsumed = errors.New("file reader already consumed")
+
+// ErrFileSizeMismatch 读取到的内容与文件大小不一致，例如上传过程中文件被修改、Size 填写错误
+var ErrFileSizeMismatch = errors.New("file size mismatch")
+
+// NewFileFromPath 上传本地文件，文件名取 path 中的文件名
+func NewFileFromPath(path string) *File {
+	return &File{Name: filepath.Base(path), Path: path}
+}
+
+// NewFileFromReader 从 r 读取上传的文件，size 未知时传 0
+func NewFileFromReader(name string, r io.Reader, size int64) *File {
+	return &File{Name: name, Reader: r, Size: size}
+}
+
+// Open 打开文件用于读取，读取后需要 Close
+func (f *File) Open() (io.ReadCloser, error) {
+	switch {
+	case f.Path != "":
+		return os.Open(f.Path)
+	case f.Reader != nil:
+		if seeker, ok := f.Reader.(io.Seeker); ok {
+			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return io.NopCloser(f.Reader), nil
+		}
+		if f.consumed {
+			return nil, ErrFileConsumed
+		}
+		f.consumed = true
+		return io.NopCloser(f.Reader), nil
+	default:
+		return io.NopCloser(bytes.NewReader(f.Content)), nil
+	}
+}
+
+// OpenLen 打开文件并返回文件大小，未知时大小为 -1，读取后需要 Close
+// Path 的大小取自打开的文件；大小已知时读取的内容与大小不一致会返回 ErrFileSizeMismatch，避免发送的内容与 Content-Length 不符
+func (f *File) OpenLen() (io.ReadCloser, int64, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var size int64
+	if file, ok := rc.(*os.File); ok {
+		info, statErr := file.Stat()
+		if statErr != nil {
+			rc.Close()
+			return nil, 0, statErr
+		}
+		size = info.Size()
+	} else if size, err = f.Len(); err != nil {
+		rc.Close()
+		return nil, 0, err
+	}
+	if size < 0 {
+		return rc, -1, nil
+	}
+	return &sizedReader{ReadCloser: rc, name: f.Name, remaining: size}, size, nil
+}
+
+// 读取的内容必须正好为 remaining 字节
+type sizedReader struct {
+	io.ReadCloser
+	name      string
+	remaining int64
+}
+
+func (r *sizedReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		// 确认已经读完
+		var b [1]byte
+		if n, _ := r.ReadCloser.Read(b[:]); n > 0 {
+			return 0, fmt.Errorf("%w: %s is larger than expected", ErrFileSizeMismatch, r.name)
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.remaining -= int64(n)
+	if err == io.EOF && r.remaining > 0 {
+		return n, fmt.Errorf("%w: %s is smaller than expected", ErrFileSizeMismatch, r.name)
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	return n, err
+}
+
+// Rewindable 是否可以重新读取，Reader 不能 Seek 时只能上传一次，不能重试
+func (f *File) Rewindable() bool {
+	if f.Path != "" || f.Reader == nil {
+		return true
+	}
+	_, ok := f.Reader.(io.Seeker)
+	return ok
+}
+
+// Len 文件大小，未知时返回 -1
+func (f *File) Len() (int64, error) {
+	switch {
+	case f.Path != "":
+		info, err := os.Stat(f.Path)
+		if err != nil {
+			return 0, err
+		}
+		return info.Size(), nil
+	case f.Reader != nil:
+		if f.Size > 0 {
+			return f.Size, nil
+		}
+		switch r := f.Reader.(type) {
+		case interface{ Size() int64 }: // bytes.Reader、strings.Reader
+			return r.Size(), nil
+		case interface{ Stat() (os.FileInfo, error) }: // os.File
+			info, err := r.Stat()
+			if err != nil {
+				return 0, err
+			}
+			return info.Size(), nil
+		}
+		return -1, nil
+	default:
+		return int64(len(f.Content)), nil
+	}
+}
@@ -9,15 +9,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"sort"
 	"strings"
 	"time"
-
-	"github.com/medreams/wechat/pkg/util"
 )
 
 type Client struct {
@@ -37,17 +34,19 @@ type Client struct {
 	err              error
 }
 
-// NewClient , default tls.Config{InsecureSkipVerify: true}
+// DefaultTransport 未设置 Transport 时共用的连接池，校验服务端证书
+var DefaultTransport = http.DefaultTransport.(*http.Transport).Clone()
+
+// DefaultHttpClient 未设置 HttpClient 时共用，复用连接
+var DefaultHttpClient = &http.Client{
+	Timeout:   60 * time.Second,
+	Transport: DefaultTransport,
+}
+
+// NewClient 默认使用 DefaultHttpClient，可替换 HttpClient 使用自己的连接池、代理、证书等
 func NewClient() (client *Client) {
 	client = &Client{
-		HttpClient: &http.Client{
-			Timeout: 60 * time.Second,
-			Transport: &http.Transport{
-				TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
-				DisableKeepAlives: true,
-				Proxy:             http.ProxyFromEnvironment,
-			},
-		},
+		HttpClient:    DefaultHttpClient,
 		Transport:     nil,
 		Header:        make(http.Header),
 		requestType:   TypeJSON,
@@ -56,13 +55,17 @@ func NewClient() (client *Client) {
 	return client
 }
 
+// SetTransport 仅对本次请求生效，不会修改 HttpClient
 func (c *Client) SetTransport(transport *http.Transport) (client *Client) {
 	c.Transport = transport
 	return c
 }
 
+// SetTLSConfig 使用 tlsCfg 创建新的 Transport，仅对本次请求生效
 func (c *Client) SetTLSConfig(tlsCfg *tls.Config) (client *Client) {
-	c.Transport = &http.Transport{TLSClientConfig: tlsCfg, DisableKeepAlives: true, Proxy: http.ProxyFromEnvironment}
+	transport := DefaultTransport.Clone()
+	transport.TLSClientConfig = tlsCfg
+	c.Transport = transport
 	return c
 }
 
@@ -244,19 +247,43 @@ func (c *Client) EndStruct(ctx context.Context, v interface{}) (res *http.Respon
 	}
 }
 
+// MaxBodySize EndBytes 读取的最大响应大小，超过时返回 ErrBodyTooLarge，更大的文件使用 EndStream
+var MaxBodySize int64 = 5 << 20
+
+// ErrBodyTooLarge 响应超过 MaxBodySize
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// EndBytes 读取全部响应，超过 MaxBodySize 时返回 ErrBodyTooLarge
 func (c *Client) EndBytes(ctx context.Context) (res *http.Response, bs []byte, err error) {
+	res, err = c.send(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+
+	bs, err = io.ReadAll(io.LimitReader(res.Body, MaxBodySize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(bs)) > MaxBodySize {
+		return nil, nil, fmt.Errorf("%w: more than %d bytes", ErrBodyTooLarge, MaxBodySize)
+	}
+	return res, bs, nil
+}
+
+// EndStream 返回未读取的响应，调用方读取 res.Body 后需要 Close
+func (c *Client) EndStream(ctx context.Context) (res *http.Response, err error) {
+	return c.send(ctx)
+}
+
+func (c *Client) send(ctx context.Context) (res *http.Response, err error) {
 	if c.err != nil {
-		return nil, nil, c.err
+		return nil, c.err
 	}
 	var (
-		body io.Reader
-		bw   *multipart.Writer
+		body          io.Reader
+		contentLength int64 = -1
 	)
-	// multipart-form-data
-	if c.requestType == TypeMultipartFormData {
-		body = &bytes.Buffer{}
-		bw = multipart.NewWriter(body.(io.Writer))
-	}
 
 	reqFunc := func() (err error) {
 		switch c.method {
@@ -267,7 +294,7 @@ func (c *Client) EndBytes(ctx context.Context) (res *http.Response, bs []byte, e
 			case TypeForm, TypeFormData, TypeUrlencoded:
 				c.ContentType = types[TypeForm]
 			case TypeMultipartFormData:
-				c.ContentType = bw.FormDataContentType()
+				c.ContentType = multipart.NewWriter(io.Discard).FormDataContentType()
 			case TypeXML:
 				c.ContentType = types[TypeXML]
 				c.unmarshalType = string(TypeXML)
@@ -285,26 +312,10 @@ func (c *Client) EndBytes(ctx context.Context) (res *http.Response, bs []byte, e
 				body = strings.NewReader(c.FormString)
 				c.ContentType = types[TypeForm]
 			case TypeMultipartFormData:
-				for k, v := range c.multipartBodyMap {
-					// file 参数
-					if file, ok := v.(*util.File); ok {
-						fw, err := bw.CreateFormFile(k, file.Name)
-						if err != nil {
-							return err
-						}
-						_, _ = fw.Write(file.Content)
-						continue
-					}
-					// text 参数
-					vs, ok2 := v.(string)
-					if ok2 {
-						_ = bw.WriteField(k, vs)
-					} else if ss := util.ConvertToString(v); ss != "" {
-						_ = bw.WriteField(k, ss)
-					}
+				// 文件流式读取，不会一次读入内存
+				if body, c.ContentType, contentLength, err = newMultipartBody(c.multipartBodyMap); err != nil {
+					return err
 				}
-				_ = bw.Close()
-				c.ContentType = bw.FormDataContentType()
 			case TypeXML:
 				body = strings.NewReader(c.FormString)
 				c.ContentType = types[TypeXML]
@@ -318,35 +329,46 @@ func (c *Client) EndBytes(ctx context.Context) (res *http.Response, bs []byte, e
 
 		req, err := http.NewRequestWithContext(ctx, c.method, c.url, body)
 		if err != nil {
+			if closer, ok := body.(io.Closer); ok {
+				closer.Close()
+			}
 			return err
 		}
+		if c.requestType == TypeMultipartFormData && body != nil {
+			req.ContentLength = contentLength
+		}
 		req.Header = c.Header
 		req.Header.Set("Content-Type", c.ContentType)
-		if c.Transport != nil {
-			c.HttpClient.Transport = c.Transport
-		}
 		if c.Host != "" {
 			req.Host = c.Host
 		}
-		if c.Timeout > 0 {
-			c.HttpClient.Timeout = c.Timeout
-		}
-		res, err = c.HttpClient.Do(req)
-		if err != nil {
-			return err
-		}
-		defer res.Body.Close()
-		bs, err = ioutil.ReadAll(io.LimitReader(res.Body, int64(5<<20))) // default 5MB change the size you want
-		if err != nil {
-			return err
-		}
-		return nil
+		res, err = c.httpClient().Do(req)
+		return err
 	}
 
 	if err = reqFunc(); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	return res, bs, nil
+	return res, nil
+}
+
+// HttpClient 可能被多个请求共用，设置了 Transport、Timeout 时使用副本
+func (c *Client) httpClient() *http.Client {
+	hc := c.HttpClient
+	if hc == nil {
+		hc = DefaultHttpClient
+	}
+	if c.Transport == nil && c.Timeout <= 0 {
+		return hc
+	}
+	clone := *hc
+	if c.Transport != nil {
+		clone.Transport = c.Transport
+	}
+	if c.Timeout > 0 {
+		clone.Timeout = c.Timeout
+	}
+	return &clone
 }
 
 func FormatURLParam(body map[string]interface{}) (urlParam string) {
@@ -0,0 +1,116 @@
+package xhttp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+
+	"github.com/medreams/wechat/pkg/util"
+)
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// 按顺序读取多个 Reader，Close 时关闭所有打开的文件
+type multipartBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *multipartBody) Close() error {
+	var errs []error
+	for _, c := range b.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// 流式编码 multipart/form-data，文件在发送时才读取；文件大小都已知时返回 Content-Length，否则返回 -1
+func newMultipartBody(bm map[string]interface{}) (body io.ReadCloser, contentType string, length int64, err error) {
+	var (
+		buf     = &bytes.Buffer{}
+		bw      = multipart.NewWriter(buf)
+		readers []io.Reader
+		mb      = &multipartBody{}
+	)
+	defer func() {
+		if err != nil {
+			mb.Close()
+		}
+	}()
+	// multipart.Writer 直接写入 buf，将已写入的边界、字段移到 readers 中
+	flush := func() {
+		if buf.Len() > 0 {
+			readers = append(readers, bytes.NewReader(append([]byte(nil), buf.Bytes()...)))
+			length += int64(buf.Len())
+			buf.Reset()
+		}
+	}
+
+	unknown := false
+	for k, v := range bm {
+		// file 参数
+		if file, ok := v.(*util.File); ok {
+			rc, size, err := file.OpenLen()
+			if err != nil {
+				return nil, "", 0, err
+			}
+			mb.closers = append(mb.closers, rc)
+
+			br := bufio.NewReaderSize(rc, 512)
+			head, err := br.Peek(512)
+			if err != nil && err != io.EOF {
+				return nil, "", 0, err
+			}
+			h := make(textproto.MIMEHeader)
+			h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(k), quoteEscaper.Replace(file.Name)))
+			h.Set("Content-Type", detectContentType(file.Name, head))
+			if _, err = bw.CreatePart(h); err != nil {
+				return nil, "", 0, err
+			}
+			flush()
+			readers = append(readers, br)
+			if size < 0 {
+				unknown = true
+			} else {
+				length += size
+			}
+			continue
+		}
+		// text 参数
+		vs, ok2 := v.(string)
+		if ok2 {
+			_ = bw.WriteField(k, vs)
+		} else if ss := util.ConvertToString(v); ss != "" {
+			_ = bw.WriteField(k, ss)
+		}
+	}
+	if err = bw.Close(); err != nil {
+		return nil, "", 0, err
+	}
+	flush()
+
+	if unknown {
+		length = -1
+	}
+	mb.Reader = io.MultiReader(readers...)
+	return mb, bw.FormDataContentType(), length, nil
+}
+
+// 按内容判断文件类型，无法判断时按扩展名
+func detectContentType(name string, head []byte) string {
+	contentType := http.DetectContentType(head)
+	if contentType == "application/octet-stream" {
+		if byExt := mime.TypeByExtension(filepath.Ext(name)); byExt != "" {
+			return byExt
+		}
+	}
+	return contentType
+}
@@ -0,0 +1,185 @@
+package wechat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/medreams/wechat/common"
+	"github.com/medreams/wechat/official"
+)
+
+// AppConfig 单个公众号、小程序的配置
+type AppConfig struct {
+	AppId          string // AppID
+	AppSecret      string // AppSecret
+	OriginalId     string // 原始ID（gh_开头），即接收消息中的 ToUserName，不需要接收消息时可不填
+	MsgToken       string // 服务器配置中的令牌(Token)
+	EncodingAESKey string // 服务器配置中的消息加解密密钥
+}
+
+// Registry 在一个进程中管理多个公众号、小程序，共用 http.Client、TokenStore 等配置
+// access_token 在 TokenStore 中按 AppID 区分，多个账号共用一个存储不会互相覆盖
+type Registry struct {
+	ctx  context.Context
+	opts []Option
+	err  error // 共用配置错误，Register 时返回
+
+	mu          sync.RWMutex
+	apps        map[string]*WeChatSDK
+	originalIds map[string]string // 原始ID => AppID
+}
+
+// NewRegistry 创建多账号管理，opts 为所有账号共用的配置
+// 未设置 WithHTTPClient 时所有账号共用一个 http.Client，复用连接
+// access_token 来源按账号区分，WithTokenSource 需在 Register 时传入，在此传入时 Register 返回错误
+func NewRegistry(ctx context.Context, opts ...Option) *Registry {
+	probe := &WeChatSDK{}
+	for _, opt := range opts {
+		opt(probe)
+	}
+	var err error
+	if probe.tokenSource != nil {
+		err = errors.New("WithTokenSource cannot be shared by all apps, pass it to Register")
+	}
+
+	shared := append([]Option{}, opts...)
+	if probe.httpClient == nil {
+		shared = append(shared, WithHTTPClient(&http.Client{
+			Timeout:   60 * time.Second,
+			Transport: http.DefaultTransport.(*http.Transport).Clone(),
+		}))
+	}
+
+	return &Registry{
+		ctx:         ctx,
+		opts:        shared,
+		err:         err,
+		apps:        make(map[string]*WeChatSDK),
+		originalIds: make(map[string]string),
+	}
+}
+
+// Register 添加账号并获取 access_token，opts 会覆盖共用的配置，WithTokenSource 在此按账号传入；AppID 已存在时替换
+// 原始ID已被其他 AppID 使用时返回错误
+func (r *Registry) Register(cfg AppConfig, opts ...Option) (*WeChatSDK, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if cfg.AppId == "" {
+		return nil, errors.New("appid cannot be empty")
+	}
+	// 先检查一次，避免冲突时仍去获取 access_token
+	r.mu.RLock()
+	err := r.checkOriginalId(cfg)
+	r.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	appOpts := append([]Option{}, r.opts...)
+	if cfg.MsgToken != "" || cfg.EncodingAESKey != "" {
+		appOpts = append(appOpts, WithMessageConfig(cfg.MsgToken, cfg.EncodingAESKey))
+	}
+	appOpts = append(appOpts, opts...)
+
+	sdk, err := New(r.ctx, cfg.AppId, cfg.AppSecret, appOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("register %s: %w", cfg.AppId, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err = r.checkOriginalId(cfg); err != nil {
+		return nil, err
+	}
+	for originalId, appId := range r.originalIds {
+		if appId == cfg.AppId {
+			delete(r.originalIds, originalId)
+		}
+	}
+	r.apps[cfg.AppId] = sdk
+	if cfg.OriginalId != "" {
+		r.originalIds[cfg.OriginalId] = cfg.AppId
+	}
+
+	return sdk, nil
+}
+
+// 原始ID是否已被其他 AppID 使用，调用时需持有 r.mu
+func (r *Registry) checkOriginalId(cfg AppConfig) error {
+	if cfg.OriginalId == "" {
+		return nil
+	}
+	if appId, ok := r.originalIds[cfg.OriginalId]; ok && appId != cfg.AppId {
+		return fmt.Errorf("original id %s already registered by %s", cfg.OriginalId, appId)
+	}
+	return nil
+}
+
+// Remove 移除账号
+func (r *Registry) Remove(appId string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.apps, appId)
+	for originalId, id := range r.originalIds {
+		if id == appId {
+			delete(r.originalIds, originalId)
+		}
+	}
+}
+
+// Get 通过 AppID 获取账号
+func (r *Registry) Get(appId string) (*WeChatSDK, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sdk, ok := r.apps[appId]
+	return sdk, ok
+}
+
+// GetByOriginalId 通过原始ID（gh_开头）获取账号
+func (r *Registry) GetByOriginalId(originalId string) (*WeChatSDK, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	appId, ok := r.originalIds[originalId]
+	if !ok {
+		return nil, false
+	}
+	sdk, ok := r.apps[appId]
+	return sdk, ok
+}
+
+// GetByMessage 通过接收到的消息的 ToUserName 获取对应的公众号
+func (r *Registry) GetByMessage(msg *official.ReceivingMessage) (*WeChatSDK, bool) {
+	if msg == nil {
+		return nil, false
+	}
+	return r.GetByOriginalId(msg.GetOfficialId())
+}
+
+// TokenRelayHandler 将所有账号的 access_token 提供给内部其他服务，请求时通过 appid 参数区分账号
+func (r *Registry) TokenRelayHandler(secret string) http.Handler {
+	return common.NewTokenRelayHandler(secret, func(appid string) (common.AccessTokenProvider, bool) {
+		sdk, ok := r.Get(appid)
+		if !ok {
+			return nil, false
+		}
+		return sdk.accessTokenProvider(), true
+	})
+}
+
+// AppIds 已添加的所有 AppID
+func (r *Registry) AppIds() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	appIds := make([]string, 0, len(r.apps))
+	for appId := range r.apps {
+		appIds = append(appIds, appId)
+	}
+	sort.Strings(appIds)
+	return appIds
+}
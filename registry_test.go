@@ -0,0 +1,100 @@
+package wechat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/medreams/wechat/common"
+	"github.com/medreams/wechat/official"
+)
+
+// 按请求中的 appid 返回 token-appid
+func newStableTokenServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			AppId string `json:"appid"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		fmt.Fprintf(w, `{"access_token":"token-%s","expires_in":7200}`, req.AppId)
+	}))
+}
+
+func TestRegistry(t *testing.T) {
+	srv := newStableTokenServer()
+	defer srv.Close()
+	r := NewRegistry(context.Background(), WithBaseURL(srv.URL), WithFailoverBaseURLs())
+
+	a, err := r.Register(AppConfig{AppId: "wx_a", AppSecret: "secret_a", OriginalId: "gh_a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := r.Register(AppConfig{AppId: "wx_b", OriginalId: "gh_b"}, WithTokenSource(common.StaticTokenSource("static-b")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 每个账号使用自己的 access_token
+	if token := a.GetAccessToken(); token != "token-wx_a" {
+		t.Fatalf("wx_a token = %s", token)
+	}
+	if token := b.GetAccessToken(); token != "static-b" {
+		t.Fatalf("wx_b token = %s", token)
+	}
+
+	if sdk, ok := r.Get("wx_a"); !ok || sdk != a {
+		t.Fatal("get wx_a")
+	}
+	if sdk, ok := r.GetByOriginalId("gh_a"); !ok || sdk != a {
+		t.Fatal("get gh_a")
+	}
+	if sdk, ok := r.GetByMessage(&official.ReceivingMessage{ToUserName: "gh_a"}); !ok || sdk != a {
+		t.Fatal("get by message")
+	}
+	if _, ok := r.GetByOriginalId("gh_unknown"); ok {
+		t.Fatal("unknown original id found")
+	}
+
+	// 原始ID已被其他账号使用
+	if _, err = r.Register(AppConfig{AppId: "wx_c", AppSecret: "secret_c", OriginalId: "gh_a"}); err == nil {
+		t.Fatal("expected original id conflict")
+	}
+	if sdk, _ := r.GetByOriginalId("gh_a"); sdk != a {
+		t.Fatal("original id taken over")
+	}
+	if _, ok := r.Get("wx_c"); ok {
+		t.Fatal("conflicting app registered")
+	}
+
+	// 同一 AppID 重新注册时更换原始ID
+	a2, err := r.Register(AppConfig{AppId: "wx_a", AppSecret: "secret_a", OriginalId: "gh_a2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.GetByOriginalId("gh_a"); ok {
+		t.Fatal("old original id still mapped")
+	}
+	if sdk, _ := r.GetByOriginalId("gh_a2"); sdk != a2 {
+		t.Fatal("get gh_a2")
+	}
+
+	r.Remove("wx_a")
+	if _, ok := r.Get("wx_a"); ok {
+		t.Fatal("removed app found")
+	}
+	if _, ok := r.GetByOriginalId("gh_a2"); ok {
+		t.Fatal("removed original id found")
+	}
+	if ids := r.AppIds(); len(ids) != 1 || ids[0] != "wx_b" {
+		t.Fatalf("app ids: %v", ids)
+	}
+}
+
+func TestRegistrySharedTokenSource(t *testing.T) {
+	r := NewRegistry(context.Background(), WithTokenSource(&common.HTTPTokenSource{URL: "http://127.0.0.1", AppId: "wx_a"}))
+	if _, err := r.Register(AppConfig{AppId: "wx_b"}); err == nil {
+		t.Fatal("expected error for token source shared by all apps")
+	}
+}
@@ -2,95 +2,337 @@ package wechat
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/medreams/wechat/common"
 	"github.com/medreams/wechat/mini"
 	"github.com/medreams/wechat/official"
 	"github.com/medreams/wechat/open"
-	"github.com/medreams/wechat/pkg/cache"
 	"github.com/medreams/wechat/we"
 )
 
 type WeChatSDK struct {
-	ctx       context.Context
-	AppId     string
-	AppSecret string
-	tokenInfo common.WxAccessToken
-	store     *cache.Cache
+	ctx            context.Context
+	AppId          string
+	AppSecret      string
+	store          common.TokenStore
+	renewBefore    time.Duration
+	httpClient     *http.Client
+	transport      http.RoundTripper
+	interceptors   []common.Interceptor
+	rateLimits     map[string]common.RateLimit
+	logger         common.Logger
+	slog           *slog.Logger
+	baseURL        string
+	failover       []string
+	timeout        time.Duration
+	retry          common.RetryPolicy
+	msgToken       string
+	encodingAESKey string
+	tokenSource    common.TokenSource
+	tokens         *common.TokenManager
+	client         *common.Client
 }
 
+type Option func(sdk *WeChatSDK)
+
+// WithTokenStore 设置 access_token 的存储，默认为进程内存储；多实例部署时使用共享存储
+func WithTokenStore(store common.TokenStore) Option {
+	return func(sdk *WeChatSDK) {
+		if store != nil {
+			sdk.store = store
+		}
+	}
+}
+
+// WithTokenSource 使用外部的 access_token 来源（如中控服务器），此时不需要 AppSecret，SDK 不会自己获取 access_token
+func WithTokenSource(ts common.TokenSource) Option {
+	return func(sdk *WeChatSDK) {
+		sdk.tokenSource = ts
+	}
+}
+
+// WithRenewBefore 设置 access_token 过期前多久主动刷新，默认5分钟
+func WithRenewBefore(d time.Duration) Option {
+	return func(sdk *WeChatSDK) {
+		if d > 0 {
+			sdk.renewBefore = d
+		}
+	}
+}
+
+// WithHTTPClient 设置调用接口、获取 access_token 使用的 http.Client，默认共用一个校验证书、复用连接的 http.Client
+func WithHTTPClient(hc *http.Client) Option {
+	return func(sdk *WeChatSDK) {
+		sdk.httpClient = hc
+	}
+}
+
+// WithTransport 设置发起请求使用的 http.RoundTripper，可用于代理、双向证书、链路追踪等
+// 同时设置 WithHTTPClient 时替换其 Transport，不会修改传入的 http.Client
+func WithTransport(rt http.RoundTripper) Option {
+	return func(sdk *WeChatSDK) {
+		sdk.transport = rt
+	}
+}
+
+// WithInterceptors 添加请求拦截器，按添加顺序执行，获取 access_token 的请求也会经过拦截器
+func WithInterceptors(interceptors ...common.Interceptor) Option {
+	return func(sdk *WeChatSDK) {
+		sdk.interceptors = append(sdk.interceptors, interceptors...)
+	}
+}
+
+// WithRateLimits 按接口路径限流，如 {"/cgi-bin/message/template/send": common.PerSecond(50)}
+// 每个 AppID 单独计算，Registry 中多个账号使用同一配置时互不影响
+func WithRateLimits(limits map[string]common.RateLimit) Option {
+	return func(sdk *WeChatSDK) {
+		sdk.rateLimits = limits
+	}
+}
+
+// WithLogger 使用 Printf 输出 Info 及以上级别的日志，默认不输出
+func WithLogger(logger common.Logger) Option {
+	return func(sdk *WeChatSDK) {
+		sdk.logger = logger
+	}
+}
+
+// WithSlog 使用 slog 输出日志，默认不输出
+// 每次请求输出一条 Info 日志，请求体、响应体只在 Debug 级别输出，access_token、secret、session_key、手机号等已隐藏
+func WithSlog(logger *slog.Logger) Option {
+	return func(sdk *WeChatSDK) {
+		sdk.slog = logger
+	}
+}
+
+// WithBaseURL 设置接口域名，默认 https://api.weixin.qq.com，可设置为 https://sh.api.weixin.qq.com 等地域域名或本地测试服务
+func WithBaseURL(baseURL string) Option {
+	return func(sdk *WeChatSDK) {
+		sdk.baseURL = baseURL
+	}
+}
+
+// WithFailoverBaseURLs 设置备用域名，主域名网络不可用时依次切换；不传参数时不切换
+// 未设置时，使用默认域名则切换到 https://api2.weixin.qq.com
+func WithFailoverBaseURLs(baseURLs ...string) Option {
+	return func(sdk *WeChatSDK) {
+		sdk.failover = append([]string{}, baseURLs...)
+	}
+}
+
+// WithTimeout 设置单次接口调用的超时时间
+func WithTimeout(timeout time.Duration) Option {
+	return func(sdk *WeChatSDK) {
+		sdk.timeout = timeout
+	}
+}
+
+// WithRetryPolicy 设置接口调用失败的重试策略
+func WithRetryPolicy(policy common.RetryPolicy) Option {
+	return func(sdk *WeChatSDK) {
+		sdk.retry = policy
+	}
+}
+
+// WithMessageConfig 设置公众号服务器配置中的令牌(Token)和消息加解密密钥(EncodingAESKey)
+func WithMessageConfig(token, encodingAESKey string) Option {
+	return func(sdk *WeChatSDK) {
+		sdk.msgToken = token
+		sdk.encodingAESKey = encodingAESKey
+	}
+}
+
+// New 创建 SDK 并获取 access_token，存储中已有未过期的 access_token 时直接使用
+func New(ctx context.Context, appId, appSecret string, opts ...Option) (*WeChatSDK, error) {
+	sdk := newWeChatSDK(ctx, appId, appSecret, opts...)
+	if err := sdk.validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := sdk.client.TokenSource().Token(ctx); err != nil {
+		return nil, fmt.Errorf("get access_token: %w", err)
+	}
+
+	return sdk, nil
+}
+
+// NewWeChatSDK 创建 SDK，isAccessToken 为 true 时立即获取 access_token
+//
+// Deprecated: 使用 New，New 会返回配置错误和获取 access_token 的错误
 func NewWeChatSDK(ctx context.Context, appId, appSecret string, isAccessToken ...bool) *WeChatSDK {
+	sdk := newWeChatSDK(ctx, appId, appSecret)
+
+	//如果需要自动获取access_token，则自动获取，获取失败时在第一次调用接口时重试
+	if len(isAccessToken) > 0 && isAccessToken[0] {
+		sdk.tokens.AccessToken(ctx)
+	}
+
+	return sdk
+}
 
+func newWeChatSDK(ctx context.Context, appId, appSecret string, opts ...Option) *WeChatSDK {
 	sdk := &WeChatSDK{
 		ctx:       ctx,
 		AppId:     appId,
 		AppSecret: appSecret,
 	}
-
-	//如果需要自动获取access_token，则自动获取
-	if len(isAccessToken) > 0 && isAccessToken[0] {
-		var acc *common.WxAccessToken
-		var err error
-
-		accessTokenCacheKey := fmt.Sprintf("%s_access_token", appId)
-		cacheWithoutClean := cache.NewCache(0)
-		sdk.store = cacheWithoutClean
-		value, found := cacheWithoutClean.Get(accessTokenCacheKey)
-
-		if found {
-			json.Unmarshal([]byte(value), acc)
-		} else {
-			acc, err = common.GetStableToken(sdk.ctx, sdk.AppId, sdk.AppSecret)
-			if err != nil {
-				fmt.Println(err)
-			}
-
-			by, err := json.Marshal(acc)
-			if err == nil {
-				cacheWithoutClean.Set(accessTokenCacheKey, string(by), acc.ExpiresIn)
-			}
+	for _, opt := range opts {
+		opt(sdk)
+	}
+	if sdk.store == nil {
+		sdk.store = common.NewMemoryTokenStore()
+	}
+	if sdk.transport != nil {
+		hc := &http.Client{Timeout: 60 * time.Second}
+		if sdk.httpClient != nil {
+			*hc = *sdk.httpClient
 		}
+		hc.Transport = sdk.transport
+		sdk.httpClient = hc
+	}
 
-		if acc != nil {
-			sdk.SetAccessToken(*acc)
+	sdk.tokens = common.NewTokenManager(appId, appSecret, sdk.store)
+	if sdk.renewBefore > 0 {
+		sdk.tokens.SetRenewBefore(sdk.renewBefore)
+	}
+	var ts common.TokenSource = sdk.tokens
+	if sdk.tokenSource != nil {
+		// 请求中控服务器也使用注入的 http.Client，使用副本不修改调用方传入的 HTTPTokenSource
+		if hts, ok := sdk.tokenSource.(*common.HTTPTokenSource); ok {
+			sdk.tokenSource = hts.WithHTTPClient(sdk.httpClient)
 		}
+		ts = sdk.tokenSource
+	}
+	clientOpts := []common.ClientOption{
+		common.WithAppId(appId),
+		common.WithHTTPClient(sdk.httpClient),
+		common.WithLogger(sdk.logger),
+		common.WithSlog(sdk.slog),
+		common.WithBaseURL(sdk.baseURL),
+		common.WithTimeout(sdk.timeout),
+		common.WithRetryPolicy(sdk.retry),
+		common.WithInterceptors(sdk.interceptors...),
+	}
+	if len(sdk.rateLimits) > 0 {
+		clientOpts = append(clientOpts, common.WithRateLimiter(common.NewRateLimiter(sdk.rateLimits)))
 	}
+	if sdk.failover != nil {
+		clientOpts = append(clientOpts, common.WithFailoverBaseURLs(sdk.failover...))
+	}
+	sdk.client = common.NewClient(ts, clientOpts...)
+	sdk.tokens.SetClient(sdk.client)
 
 	return sdk
 }
 
+// 检查配置
+func (sdk *WeChatSDK) validate() error {
+	if sdk.AppId == "" {
+		return errors.New("appid cannot be empty")
+	}
+	if sdk.AppSecret == "" && sdk.tokenSource == nil {
+		return errors.New("appsecret cannot be empty")
+	}
+	if sdk.baseURL != "" {
+		if err := validateBaseURL(sdk.baseURL); err != nil {
+			return err
+		}
+	}
+	for _, baseURL := range sdk.failover {
+		if err := validateBaseURL(baseURL); err != nil {
+			return err
+		}
+	}
+	if sdk.timeout < 0 {
+		return fmt.Errorf("invalid timeout %s", sdk.timeout)
+	}
+	if sdk.encodingAESKey != "" && len(sdk.encodingAESKey) != 43 {
+		return errors.New("the length of EncodingAESKey must be equal to 43")
+	}
+	return nil
+}
+
+func validateBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base url %s: %w", baseURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid base url %s", baseURL)
+	}
+	return nil
+}
+
 // 小程序
 func (sdk *WeChatSDK) NewMini() *mini.SDK {
-	return mini.New(sdk.AppId, sdk.AppSecret, sdk.tokenInfo.AccessToken)
+	return mini.NewWithClient(sdk.AppId, sdk.AppSecret, sdk.client)
 }
 
 // 公众号
 func (sdk *WeChatSDK) NewOfficial() *official.SDK {
-	return official.New(sdk.AppId, sdk.AppSecret, sdk.tokenInfo.AccessToken)
+	sdkOfficial := official.NewWithClient(sdk.AppId, sdk.AppSecret, sdk.client)
+	sdkOfficial.SetMessageConfig(sdk.msgToken, sdk.encodingAESKey)
+	return sdkOfficial
 }
 
 // 开放平台
 func (sdk *WeChatSDK) NewOpen() *open.SDK {
-	return open.New(sdk.AppId, sdk.AppSecret, sdk.tokenInfo.AccessToken)
+	return open.NewWithClient(sdk.AppId, sdk.AppSecret, sdk.client)
 }
 
 // 公共
 func (sdk *WeChatSDK) NewWe() *we.SDK {
-	return we.New(sdk.AppId, sdk.AppSecret, sdk.tokenInfo.AccessToken)
+	return we.NewWithClient(sdk.AppId, sdk.AppSecret, sdk.client)
 }
 
+// TokenManager access_token 管理器，未设置 WithTokenSource 时子 SDK 每次请求都从这里获取 access_token
+func (sdk *WeChatSDK) TokenManager() *common.TokenManager {
+	return sdk.tokens
+}
+
+// TokenRelayHandler 将本 SDK 管理的 access_token 提供给内部其他服务，作为中控服务器使用
+// 其他服务使用 common.HTTPTokenSource（Secret 与 secret 相同）获取 access_token
+func (sdk *WeChatSDK) TokenRelayHandler(secret string) http.Handler {
+	return common.NewTokenRelayHandler(secret, func(appid string) (common.AccessTokenProvider, bool) {
+		if appid != "" && appid != sdk.AppId {
+			return nil, false
+		}
+		return sdk.accessTokenProvider(), true
+	})
+}
+
+// 当前使用的 access_token 来源，外部来源无法提供过期时间时返回 nil
+func (sdk *WeChatSDK) accessTokenProvider() common.AccessTokenProvider {
+	if sdk.tokenSource == nil {
+		return sdk.tokens
+	}
+	provider, _ := sdk.tokenSource.(common.AccessTokenProvider)
+	return provider
+}
+
+// SetAccessToken 设置外部获取的 access_token，设置了 WithTokenSource 时 access_token 由外部来源管理，返回错误
 func (sdk *WeChatSDK) SetAccessToken(token common.WxAccessToken) (err error) {
-	sdk.tokenInfo = token
-	return nil
+	if sdk.tokenSource != nil {
+		return errors.New("access_token is managed by the token source")
+	}
+	return sdk.tokens.SetAccessToken(sdk.ctx, token)
 }
 
 func (sdk *WeChatSDK) GetAccessToken() (access_token string) {
-	return sdk.tokenInfo.AccessToken
+	access_token, _ = sdk.client.TokenSource().Token(sdk.ctx)
+	return access_token
 }
 
+// CleanAccessToken 清除本地和存储中的 access_token，设置了 WithTokenSource 时不做任何操作
 func (sdk *WeChatSDK) CleanAccessToken() {
-	accessTokenCacheKey := fmt.Sprintf("%s_access_token", sdk.AppId)
-	sdk.store.Del(accessTokenCacheKey)
+	if sdk.tokenSource != nil {
+		return
+	}
+	sdk.tokens.Clean(sdk.ctx)
 }
@@ -0,0 +1,98 @@
+package wechat
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/medreams/wechat/common"
+)
+
+func TestNew(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "bad_secret") {
+			w.Write([]byte(`{"errcode":40125,"errmsg":"invalid appsecret"}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"token","expires_in":7200}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		appId     string
+		appSecret string
+		opts      []Option
+		wantErr   string
+	}{
+		{name: "ok", appId: "wx_new", appSecret: "secret"},
+		{name: "token source without secret", appId: "wx_new", opts: []Option{WithTokenSource(common.StaticTokenSource("token"))}},
+		{name: "empty appid", appSecret: "secret", wantErr: "appid cannot be empty"},
+		{name: "empty secret", appId: "wx_new", wantErr: "appsecret cannot be empty"},
+		{name: "malformed base url", appId: "wx_new", appSecret: "secret", opts: []Option{WithBaseURL("api.weixin.qq.com")}, wantErr: "invalid base url"},
+		{name: "malformed failover url", appId: "wx_new", appSecret: "secret", opts: []Option{WithFailoverBaseURLs("ftp://api2.weixin.qq.com")}, wantErr: "invalid base url"},
+		{name: "encoding aes key length", appId: "wx_new", appSecret: "secret", opts: []Option{WithMessageConfig("token", "short")}, wantErr: "EncodingAESKey"},
+		{name: "negative timeout", appId: "wx_new", appSecret: "secret", opts: []Option{WithTimeout(-time.Second)}, wantErr: "invalid timeout"},
+		{name: "get access_token", appId: "wx_new", appSecret: "bad_secret", wantErr: "get access_token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithBaseURL(srv.URL), WithFailoverBaseURLs()}, tt.opts...)
+			sdk, err := New(context.Background(), tt.appId, tt.appSecret, opts...)
+			if tt.wantErr == "" {
+				if err != nil || sdk == nil {
+					t.Fatalf("New() = %v, %v", sdk, err)
+				}
+				if token := sdk.GetAccessToken(); token != "token" {
+					t.Fatalf("GetAccessToken() = %s", token)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("New() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestNewHTTPTokenSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"relayed","expires_in":7200}`))
+	}))
+	defer srv.Close()
+
+	var calls int32
+	hc := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&calls, 1)
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+	hts := &common.HTTPTokenSource{URL: srv.URL, AppId: "wx_relay"}
+
+	sdk, err := New(context.Background(), "wx_relay", "", WithTokenSource(hts), WithHTTPClient(hc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 请求中控服务器使用注入的 http.Client
+	if token := sdk.GetAccessToken(); token != "relayed" || atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("GetAccessToken() = %s, calls = %d", token, calls)
+	}
+	// 不修改调用方传入的 HTTPTokenSource
+	if hts.HTTPClient != nil {
+		t.Fatal("caller's HTTPTokenSource modified")
+	}
+	if token, err := hts.Token(context.Background()); err != nil || token != "relayed" || atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("Token() = %s, %v, calls = %d", token, err, calls)
+	}
+}
@@ -0,0 +1,183 @@
+// Package telemetry 为微信接口请求记录 OpenTelemetry 链路和指标
+//
+// 通过 wechat.WithInterceptors(telemetry.Interceptor()) 启用，每次 HTTP 请求（包括重试、切换域名）生成一个 span，
+// 并记录调用次数、耗时和 errcode，指标按接口路径和 AppID 区分
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/medreams/wechat/common"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/medreams/wechat/telemetry"
+
+// 属性名
+const (
+	AttrAppId      = attribute.Key("wechat.appid")
+	AttrAPIPath    = attribute.Key("wechat.api.path")
+	AttrErrCode    = attribute.Key("wechat.errcode")
+	AttrMethod     = attribute.Key("http.request.method")
+	AttrStatusCode = attribute.Key("http.response.status_code")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+type Option func(c *config)
+
+// WithTracerProvider 设置 TracerProvider，默认使用 otel.GetTracerProvider()
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider 设置 MeterProvider，默认使用 otel.GetMeterProvider()
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+type instruments struct {
+	tracer   trace.Tracer
+	calls    metric.Int64Counter
+	errors   metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+// Interceptor 记录链路和指标的请求拦截器
+//
+// 指标：
+//   - wechat.api.calls 调用次数
+//   - wechat.api.duration 耗时（秒）
+//   - wechat.api.errors 失败次数，按 wechat.errcode 区分，网络错误的 wechat.errcode 为 -2，状态码不是200时带上 http.response.status_code
+func Interceptor(opts ...Option) common.Interceptor {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.tracerProvider == nil {
+		cfg.tracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.meterProvider == nil {
+		cfg.meterProvider = otel.GetMeterProvider()
+	}
+
+	ins, err := newInstruments(cfg)
+	if err != nil {
+		otel.Handle(err)
+		ins, _ = newInstruments(&config{tracerProvider: cfg.tracerProvider, meterProvider: noop.NewMeterProvider()})
+	}
+
+	return func(ctx context.Context, req *common.Request, next common.RoundTripFunc) (*common.Response, error) {
+		path := apiPath(req.URL)
+		attrs := []attribute.KeyValue{
+			AttrAPIPath.String(path),
+			AttrAppId.String(req.AppId),
+			AttrMethod.String(req.Method),
+		}
+
+		ctx, span := ins.tracer.Start(ctx, "WeChat "+path,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+
+		start := time.Now()
+		res, err := next(ctx, req)
+		elapsed := time.Since(start).Seconds()
+
+		// 失败时 errors 指标额外带上的属性
+		var errAttrs []attribute.KeyValue
+		if err != nil {
+			errAttrs = append(errAttrs, AttrErrCode.String("-2"))
+			// 错误中可能包含带 access_token 的请求地址
+			redacted := common.RedactError(err)
+			span.RecordError(redacted)
+			span.SetStatus(codes.Error, redacted.Error())
+		} else {
+			span.SetAttributes(AttrStatusCode.Int(res.StatusCode))
+			if errCode, errMsg := parseErrCode(res.Body); errCode != 0 {
+				errAttrs = append(errAttrs, AttrErrCode.String(strconv.FormatInt(errCode, 10)))
+				span.SetAttributes(AttrErrCode.Int64(errCode))
+				span.SetStatus(codes.Error, errMsg)
+			} else if res.StatusCode != http.StatusOK {
+				errAttrs = append(errAttrs, AttrStatusCode.Int(res.StatusCode))
+				span.SetStatus(codes.Error, http.StatusText(res.StatusCode))
+			}
+		}
+
+		set := metric.WithAttributes(attrs...)
+		ins.calls.Add(ctx, 1, set)
+		ins.duration.Record(ctx, elapsed, set)
+		if len(errAttrs) > 0 {
+			ins.errors.Add(ctx, 1, metric.WithAttributes(append(attrs, errAttrs...)...))
+		}
+
+		return res, err
+	}
+}
+
+func newInstruments(cfg *config) (*instruments, error) {
+	meter := cfg.meterProvider.Meter(instrumentationName)
+	ins := &instruments{
+		tracer: cfg.tracerProvider.Tracer(instrumentationName),
+	}
+
+	var err error
+	if ins.calls, err = meter.Int64Counter("wechat.api.calls",
+		metric.WithDescription("微信接口调用次数"),
+		metric.WithUnit("{call}"),
+	); err != nil {
+		return ins, err
+	}
+	if ins.errors, err = meter.Int64Counter("wechat.api.errors",
+		metric.WithDescription("微信接口调用失败次数，按 errcode 区分"),
+		metric.WithUnit("{call}"),
+	); err != nil {
+		return ins, err
+	}
+	if ins.duration, err = meter.Float64Histogram("wechat.api.duration",
+		metric.WithDescription("微信接口调用耗时"),
+		metric.WithUnit("s"),
+	); err != nil {
+		return ins, err
+	}
+	return ins, nil
+}
+
+// 接口路径，不包含 access_token 等参数
+func apiPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
+
+// 解析返回的 errcode，二维码等非 json 内容返回0
+func parseErrCode(body []byte) (int64, string) {
+	if len(body) == 0 || body[0] != '{' {
+		return 0, ""
+	}
+	rsp := &common.WxCommonResponse{}
+	if err := json.Unmarshal(body, rsp); err != nil {
+		return 0, ""
+	}
+	return rsp.ErrCode, rsp.ErrMsg
+}
@@ -0,0 +1,100 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/medreams/wechat/common"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInterceptor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errcode":45009,"errmsg":"reach max api daily quota limit"}`))
+	}))
+	defer srv.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	interceptor := Interceptor(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+
+	c := common.NewClient(common.StaticTokenSource("TOKEN"),
+		common.WithAppId("wx_telemetry"),
+		common.WithBaseURL(srv.URL),
+		common.WithInterceptors(interceptor),
+	)
+	if err := c.DoRequestGet(context.Background(), "/cgi-bin/menu/get", &common.WxCommonResponse{}); !common.IsRateLimited(err) {
+		t.Fatalf("DoRequestGet() = %v", err)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 1 || ended[0].Name() != "WeChat /cgi-bin/menu/get" {
+		t.Fatalf("unexpected spans: %v", ended)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string]bool)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			found[m.Name] = true
+			if m.Name != "wechat.api.errors" {
+				continue
+			}
+			point := m.Data.(metricdata.Sum[int64]).DataPoints[0]
+			if v, _ := point.Attributes.Value(AttrErrCode); v.AsString() != "45009" {
+				t.Fatalf("errcode = %v", v)
+			}
+			if v, _ := point.Attributes.Value(AttrAppId); v.AsString() != "wx_telemetry" {
+				t.Fatalf("appid = %v", v)
+			}
+		}
+	}
+	for _, name := range []string{"wechat.api.calls", "wechat.api.duration", "wechat.api.errors"} {
+		if !found[name] {
+			t.Fatalf("metric %s not recorded", name)
+		}
+	}
+}
+
+func TestInterceptorErrorRedacted(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	interceptor := Interceptor(WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))))
+
+	uri := "https://api.weixin.qq.com/cgi-bin/menu/get?access_token=SECRET_TOKEN"
+	next := func(ctx context.Context, req *common.Request) (*common.Response, error) {
+		return nil, fmt.Errorf("wrapped: %w", &url.Error{Op: "Get", URL: uri, Err: errors.New("connection refused")})
+	}
+	interceptor(context.Background(), &common.Request{Method: http.MethodGet, URL: uri}, next)
+
+	ended := spans.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("unexpected spans: %v", ended)
+	}
+	exported := ended[0].Status().Description
+	for _, event := range ended[0].Events() {
+		for _, attr := range event.Attributes {
+			exported += " " + attr.Value.Emit()
+		}
+	}
+	if !strings.Contains(exported, "connection refused") {
+		t.Fatalf("error not recorded: %s", exported)
+	}
+	if strings.Contains(exported, "SECRET_TOKEN") {
+		t.Fatalf("access_token exported: %s", exported)
+	}
+}
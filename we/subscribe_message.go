@@ -34,18 +34,9 @@ type WxSubscribeMessageParam struct {
 
 // GetSubscribeTemplateList 获取私有订阅模版
 func (sdk *SDK) GetSubscribeTemplateList(ctx context.Context, appid string) (*WxGetTemplateRes, error) {
-	req := &WxGetTemplateRes{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/wxaapi/newtmpl/gettemplate?access_token=%s", sdk.AccessToken)
+	uri := "/wxaapi/newtmpl/gettemplate"
 
-	if err := common.DoRequestGet(ctx, uri, req); err != nil {
-		return nil, fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return nil, fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return req, nil
+	return common.CallGet[WxGetTemplateRes](ctx, sdk.client, uri)
 }
 
 // SendSubscribeMessage 发送模版信息
@@ -69,16 +60,8 @@ func (sdk *SDK) SendSubscribeMessage(ctx context.Context, param *WxSubscribeMess
 		bodyMap.Set("miniprogram_state", param.MiniprogramState) //小程序
 	}
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/message/subscribe/send?access_token=%s", sdk.AccessToken)
-
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
+	uri := "/cgi-bin/message/subscribe/send"
 
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }
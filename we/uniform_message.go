@@ -35,16 +35,8 @@ func (sdk *SDK) SendUniformTemplateMessage(ctx context.Context, param *WxSendUni
 	bodyMap.Set("touser", param.Touser)
 	bodyMap.Set("mp_template_msg", param.MpTemplateMsg)
 
-	req := &common.WxCommonResponse{}
-	uri := fmt.Sprintf("https://api.weixin.qq.com/cgi-bin/message/wxopen/template/uniform_send?access_token=%s", sdk.AccessToken)
+	uri := "/cgi-bin/message/wxopen/template/uniform_send"
 
-	if err := common.DoRequestPost(ctx, uri, bodyMap, req); err != nil {
-		return fmt.Errorf("do request: %w", err)
-	}
-
-	if req.ErrCode != 0 {
-		return fmt.Errorf("ErrCode(%d) != 0", req.ErrCode)
-	}
-
-	return nil
+	_, err := common.Call[common.WxCommonResponse](ctx, sdk.client, uri, bodyMap)
+	return err
 }